@@ -0,0 +1,209 @@
+// Package autolink provides routines for converting text into HTML in which
+// the entities found by the extract package are wrapped in links
+//
+// All text outside of the links is HTML-escaped, so the output of AutoLink
+// is safe to embed in a page as-is.
+package autolink
+
+import (
+	"html"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/interspace/byte-text-go/extract"
+)
+
+// Link describes an anchor element that is about to be rendered for an
+// entity. Both the text and the attribute values are unescaped; they are
+// escaped when the link is written out.
+type Link struct {
+	Text  string
+	Attrs map[string]string
+}
+
+// LinkFunc is called once for every entity before its link is rendered. It
+// may modify the link text and attributes in place. Returning false renders
+// the entity as plain (escaped) text instead of a link.
+type LinkFunc func(entity *extract.ByteEntity, link *Link) bool
+
+// Args configures how AutoLink renders entities.
+//
+// The URL templates must contain a single "%s", which is replaced with the
+// path-escaped screen name, hashtag or symbol (without the leading @, # or
+// $). For lists, it is replaced with the path-escaped owner and list slug
+// separated by a slash, e.g. "owner/list-name". For federated mentions, it is
+// replaced with the path-escaped screen name and domain separated by an at
+// sign, e.g. "alice@mastodon.social". Entities whose template is empty are
+// left as plain text.
+type Args struct {
+	MentionURLTemplate string
+	ListURLTemplate    string
+	HashtagURLTemplate string
+	CashtagURLTemplate string
+
+	// The template of mentions of accounts on other servers (See:
+	// extract.ByteEntity.MentionDomain). MentionURLTemplate is never used
+	// for them, since it would link to a local account with the same name
+	FederatedMentionURLTemplate string
+
+	// CSS classes for each kind of link. Empty values are omitted
+	MentionClass string
+	ListClass    string
+	HashtagClass string
+	CashtagClass string
+	URLClass     string
+
+	// Rel is added to every link, URLTarget only to links for URL entities.
+	// Empty values are omitted
+	Rel       string
+	URLTarget string
+
+	// Callback is an optional hook run for every entity
+	Callback LinkFunc
+}
+
+// DefaultArgs returns the arguments used by the Byte clients. Federated
+// mentions are left as plain text
+func DefaultArgs() Args {
+	return Args{
+		MentionURLTemplate: "https://byte.co/%s",
+		ListURLTemplate:    "https://byte.co/%s",
+		HashtagURLTemplate: "https://byte.co/hashtag/%s",
+		CashtagURLTemplate: "https://byte.co/cashtag/%s",
+		MentionClass:       "mention",
+		ListClass:          "list",
+		HashtagClass:       "hashtag",
+		CashtagClass:       "cashtag",
+		URLClass:           "url",
+		Rel:                "nofollow",
+	}
+}
+
+// AutoLink extracts all entities from the given text and returns it as HTML,
+// with every mention, hashtag, cashtag and URL wrapped in an <a> tag
+func AutoLink(text string, args Args) string {
+	return AutoLinkEntities(text, extract.Entities(text), args)
+}
+
+// AutoLinkEntities returns the given text as HTML, wrapping the supplied
+// entities in <a> tags. The entities must not overlap and their ByteRange
+// must refer to offsets within text; they need not be sorted.
+func AutoLinkEntities(text string, entities []*extract.ByteEntity, args Args) string {
+	sorted := make([]*extract.ByteEntity, len(entities))
+	copy(sorted, entities)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ByteRange.Start < sorted[j].ByteRange.Start
+	})
+
+	var b strings.Builder
+	offset := 0
+	for _, e := range sorted {
+		if e.ByteRange.Start < offset || e.ByteRange.Stop > len(text) {
+			continue
+		}
+		b.WriteString(html.EscapeString(text[offset:e.ByteRange.Start]))
+		entityText := text[e.ByteRange.Start:e.ByteRange.Stop]
+		if link := newLink(e, entityText, args); link != nil {
+			writeLink(&b, link)
+		} else {
+			b.WriteString(html.EscapeString(entityText))
+		}
+		offset = e.ByteRange.Stop
+	}
+	b.WriteString(html.EscapeString(text[offset:]))
+	return b.String()
+}
+
+// newLink builds the link for a single entity. Returns nil when the entity
+// should be rendered as plain text
+func newLink(e *extract.ByteEntity, text string, args Args) *Link {
+	var href, class string
+	switch e.Type {
+	case extract.Mention:
+		screenName, _ := e.ScreenName()
+		if domain, ok := e.MentionDomain(); ok {
+			href = expandTemplate(args.FederatedMentionURLTemplate, "@", screenName, domain)
+		} else {
+			href = expandTemplate(args.MentionURLTemplate, "", screenName)
+		}
+		class = args.MentionClass
+	case extract.List:
+		owner, _ := e.ScreenName()
+		slug, _ := e.ListSlug()
+		href = expandTemplate(args.ListURLTemplate, "/", owner, slug)
+		class = args.ListClass
+	case extract.Hashtag:
+		hashtag, _ := e.Hashtag()
+		href = expandTemplate(args.HashtagURLTemplate, "", hashtag)
+		class = args.HashtagClass
+	case extract.Cashtag:
+		symbol, _ := e.Symbol()
+		href = expandTemplate(args.CashtagURLTemplate, "", symbol)
+		class = args.CashtagClass
+	case extract.URL:
+		href, _ = e.NormalizedURL()
+		class = args.URLClass
+	}
+	if href == "" {
+		return nil
+	}
+
+	link := &Link{Text: text, Attrs: map[string]string{"href": href}}
+	if class != "" {
+		link.Attrs["class"] = class
+	}
+	if args.Rel != "" {
+		link.Attrs["rel"] = args.Rel
+	}
+	if e.Type == extract.URL && args.URLTarget != "" {
+		link.Attrs["target"] = args.URLTarget
+	}
+
+	if args.Callback != nil && !args.Callback(e, link) {
+		return nil
+	}
+	return link
+}
+
+// expandTemplate replaces the %s in template with the values, each path
+// escaped and joined by sep, e.g. the owner and slug of a list joined by "/".
+// Returns "" if the template is empty
+func expandTemplate(template, sep string, values ...string) string {
+	if template == "" {
+		return ""
+	}
+	escaped := make([]string, len(values))
+	for i, value := range values {
+		escaped[i] = url.PathEscape(value)
+	}
+	return strings.Replace(template, "%s", strings.Join(escaped, sep), 1)
+}
+
+// writeLink renders the link as an <a> element. The href attribute is
+// always written first, followed by the remaining attributes in sorted
+// order so that the output is stable
+func writeLink(b *strings.Builder, link *Link) {
+	names := make([]string, 0, len(link.Attrs))
+	for name := range link.Attrs {
+		if name != "href" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if _, ok := link.Attrs["href"]; ok {
+		names = append([]string{"href"}, names...)
+	}
+
+	b.WriteString("<a")
+	for _, name := range names {
+		b.WriteString(" ")
+		b.WriteString(name)
+		b.WriteString(`="`)
+		b.WriteString(html.EscapeString(link.Attrs[name]))
+		b.WriteString(`"`)
+	}
+	b.WriteString(">")
+	b.WriteString(html.EscapeString(link.Text))
+	b.WriteString("</a>")
+}
@@ -0,0 +1,140 @@
+package autolink
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/interspace/byte-text-go/extract"
+	goyaml "gopkg.in/yaml.v1"
+)
+
+type Conformance struct {
+	Tests map[string][]*Test
+}
+
+type Test struct {
+	Description string
+	Text        string
+	Expected    string
+}
+
+var cwd, _ = os.Getwd()
+var parentDir = path.Dir(cwd)
+var autolinkYmlPath = path.Join(parentDir, "conformance", "autolink.yml")
+
+func TestAutoLink(t *testing.T) {
+	contents, err := ioutil.ReadFile(autolinkYmlPath)
+	if err != nil {
+		t.Errorf("Error reading autolink.yml: %v", err)
+		t.FailNow()
+	}
+
+	var conformance = &Conformance{}
+	err = goyaml.Unmarshal(contents, &conformance)
+	if err != nil {
+		t.Errorf("Error parsing autolink.yml: %v", err)
+		t.FailNow()
+	}
+
+	for _, key := range []string{"mentions", "lists", "hashtags", "cashtags", "urls", "all", "escaping"} {
+		tests, ok := conformance.Tests[key]
+		if !ok {
+			t.Errorf("Conformance file did not contain '%s' key", key)
+			continue
+		}
+
+		for _, test := range tests {
+			actual := AutoLink(test.Text, DefaultArgs())
+			if actual != test.Expected {
+				t.Errorf(
+					"AutoLink returned incorrect value for test [%s]. Expected:[%s] Got:[%s]\n",
+					test.Description,
+					test.Expected,
+					actual,
+				)
+			}
+		}
+	}
+}
+
+func TestAutoLinkFederatedMentions(t *testing.T) {
+	contents, err := ioutil.ReadFile(autolinkYmlPath)
+	if err != nil {
+		t.Errorf("Error reading autolink.yml: %v", err)
+		t.FailNow()
+	}
+
+	var conformance = &Conformance{}
+	err = goyaml.Unmarshal(contents, &conformance)
+	if err != nil {
+		t.Errorf("Error parsing autolink.yml: %v", err)
+		t.FailNow()
+	}
+
+	tests, ok := conformance.Tests["federated_mentions"]
+	if !ok {
+		t.Errorf("Conformance file did not contain 'federated_mentions' key")
+		t.FailNow()
+	}
+
+	options := extract.DefaultExtractorOptions()
+	options.FederatedMentions = true
+	x, err := extract.NewExtractor(options)
+	if err != nil {
+		t.Fatalf("NewExtractor failed: %v", err)
+	}
+
+	for _, test := range tests {
+		actual := AutoLinkEntities(test.Text, x.Entities(test.Text), DefaultArgs())
+		if actual != test.Expected {
+			t.Errorf(
+				"AutoLinkEntities returned incorrect value for test [%s]. Expected:[%s] Got:[%s]\n",
+				test.Description,
+				test.Expected,
+				actual,
+			)
+		}
+	}
+}
+
+func ExampleAutoLink() {
+	args := Args{
+		MentionURLTemplate: "/users/%s",
+		URLClass:           "external",
+		URLTarget:          "_blank",
+	}
+	fmt.Println(AutoLink("@username shared http://example.com #tag", args))
+	// Output:
+	// <a href="/users/username">@username</a> shared <a href="http://example.com" class="external" target="_blank">http://example.com</a> #tag
+}
+
+func ExampleLinkFunc() {
+	args := DefaultArgs()
+	args.Callback = func(e *extract.ByteEntity, link *Link) bool {
+		if e.Type == extract.Mention {
+			link.Attrs["data-screen-name"] = link.Text[1:]
+		}
+		return e.Type != extract.Hashtag
+	}
+	fmt.Println(AutoLink("@username #tag", args))
+	// Output:
+	// <a href="https://byte.co/username" class="mention" data-screen-name="username" rel="nofollow">@username</a> #tag
+}
+
+func ExampleArgs_federatedMentions() {
+	options := extract.DefaultExtractorOptions()
+	options.FederatedMentions = true
+	x, _ := extract.NewExtractor(options)
+
+	text := "@bob and @bob@example.social"
+	args := Args{
+		MentionURLTemplate:          "/users/%s",
+		FederatedMentionURLTemplate: "/remote/%s",
+	}
+	fmt.Println(AutoLinkEntities(text, x.Entities(text), args))
+	// Output:
+	// <a href="/users/bob">@bob</a> and <a href="/remote/bob@example.social">@bob@example.social</a>
+}
@@ -0,0 +1,36 @@
+// Command bytetext-server serves the extract and validate packages as a JSON
+// HTTP service. See the server package for the endpoints.
+//
+// Usage:
+//
+//	bytetext-server [-addr :8080] [-maxRequestBytes n] [-maxBatchSize n]
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/interspace/byte-text-go/server"
+)
+
+func main() {
+	config := server.DefaultConfig()
+	addr := flag.String("addr", ":8080", "the address to listen on")
+	flag.Int64Var(&config.MaxRequestBytes, "maxRequestBytes", config.MaxRequestBytes, "the maximum size of a request body in bytes")
+	flag.IntVar(&config.MaxBatchSize, "maxBatchSize", config.MaxBatchSize, "the maximum number of requests in a batch")
+	flag.Parse()
+
+	// Bound the time a client may hold a connection, since requests are
+	// small and quick to serve
+	s := &http.Server{
+		Addr:              *addr,
+		Handler:           server.NewHandler(config),
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       10 * time.Second,
+		WriteTimeout:      30 * time.Second,
+	}
+	log.Printf("bytetext-server listening on %s", *addr)
+	log.Fatal(s.ListenAndServe())
+}
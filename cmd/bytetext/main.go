@@ -0,0 +1,355 @@
+// Command bytetext runs the extract and validate packages on a piece of
+// text, to help explain how a text is parsed.
+//
+// Usage:
+//
+//	bytetext <command> [flags] [text ...]
+//
+// The commands are:
+//
+//	extract   list the entities found in the text
+//	validate  check whether the text (or URL) is valid
+//	length    print the length and weighted length of the text
+//	username  check whether the text is a valid @username
+//
+// The text is taken from the arguments, joined by spaces, or read from
+// standard input when there are none; a single trailing newline is removed
+// from standard input. Results are printed as a table, or as JSON with the
+// -json flag. validate and username exit with status 1 if the text is
+// invalid.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/interspace/byte-text-go/extract"
+	"github.com/interspace/byte-text-go/validate"
+)
+
+// Exit statuses
+const (
+	exitOK      = 0
+	exitInvalid = 1
+	exitUsage   = 2
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(c *context) int
+}
+
+var commands = []command{
+	{"extract", "list the entities found in the text", runExtract},
+	{"validate", "check whether the text (or URL) is valid", runValidate},
+	{"length", "print the length and weighted length of the text", runLength},
+	{"username", "check whether the text is a valid @username", runUsername},
+}
+
+// context holds the state shared by all commands
+type context struct {
+	args   []string
+	flags  *flag.FlagSet
+	json   *bool
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command line args and returns the exit status
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return exitUsage
+	}
+
+	for _, cmd := range commands {
+		if cmd.name != args[0] {
+			continue
+		}
+		flags := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+		flags.SetOutput(stderr)
+		c := &context{
+			args:   args[1:],
+			flags:  flags,
+			json:   flags.Bool("json", false, "print the result as JSON"),
+			stdin:  stdin,
+			stdout: stdout,
+			stderr: stderr,
+		}
+		return cmd.run(c)
+	}
+
+	if args[0] != "help" && args[0] != "-h" && args[0] != "-help" {
+		fmt.Fprintf(stderr, "bytetext: unknown command %q\n", args[0])
+	}
+	usage(stderr)
+	return exitUsage
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: bytetext <command> [flags] [text ...]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-9s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run 'bytetext <command> -h' for the flags of a command.")
+}
+
+// parse parses the command's flags and returns the input text
+func (c *context) parse() (string, error) {
+	if err := c.flags.Parse(c.args); err != nil {
+		return "", err
+	}
+	if c.flags.NArg() > 0 {
+		return strings.Join(c.flags.Args(), " "), nil
+	}
+
+	data, err := ioutil.ReadAll(c.stdin)
+	if err != nil {
+		return "", err
+	}
+	text := strings.TrimSuffix(string(data), "\n")
+	return strings.TrimSuffix(text, "\r"), nil
+}
+
+// printJSON writes v as indented JSON
+func (c *context) printJSON(v interface{}) error {
+	enc := json.NewEncoder(c.stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// printTable writes the rows as aligned, tab separated columns
+func (c *context) printTable(rows [][]string) error {
+	w := tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+// fail reports an error and returns the matching exit status
+func (c *context) fail(err error) int {
+	if err == flag.ErrHelp {
+		return exitUsage
+	}
+	fmt.Fprintf(c.stderr, "bytetext %s: %v\n", c.flags.Name(), err)
+	return exitUsage
+}
+
+func runExtract(c *context) int {
+	types := c.flags.String("types", "", "comma separated entity types to list, e.g. Mention,URL (default all)")
+	requireProtocol := c.flags.Bool("requireProtocol", false, "only extract URLs starting with http:// or https://")
+	federatedMentions := c.flags.Bool("federatedMentions", false, "extract mentions of accounts on other servers, e.g. @alice@mastodon.social")
+	protectedDomains := c.flags.String("protectedDomains", "", "comma separated domains whose lookalikes are reported in -json output")
+	text, err := c.parse()
+	if err != nil {
+		return c.fail(err)
+	}
+
+	options := extract.DefaultExtractorOptions()
+	options.ExtractURLsWithoutProtocol = !*requireProtocol
+	options.FederatedMentions = *federatedMentions
+	if *types != "" {
+		for _, name := range strings.Split(*types, ",") {
+			t, ok := extract.ParseEntityType(strings.TrimSpace(name))
+			if !ok {
+				return c.fail(fmt.Errorf("unknown entity type %q", name))
+			}
+			options.EntityTypes = append(options.EntityTypes, t)
+		}
+	}
+	options.ProtectedDomains = splitList(*protectedDomains)
+	extractor, err := extract.NewExtractor(options)
+	if err != nil {
+		return c.fail(err)
+	}
+
+	entities := extractor.Entities(text)
+	if *c.json {
+		if entities == nil {
+			entities = []*extract.ByteEntity{}
+		}
+		if err := c.printJSON(entities); err != nil {
+			return c.fail(err)
+		}
+		return exitOK
+	}
+
+	rows := [][]string{{"TYPE", "TEXT", "VALUE", "CHARS", "BYTES", "UTF16"}}
+	for _, e := range entities {
+		rows = append(rows, []string{
+			e.Type.String(),
+			e.Text,
+			entityValue(e),
+			e.Range.String(),
+			e.ByteRange.String(),
+			e.UTF16Range.String(),
+		})
+	}
+	if err := c.printTable(rows); err != nil {
+		return c.fail(err)
+	}
+	return exitOK
+}
+
+// entityValue returns the screen name, hashtag, symbol or normalized URL of
+// the entity
+func entityValue(e *extract.ByteEntity) string {
+	if v, ok := e.ScreenName(); ok {
+		if domain, ok := e.MentionDomain(); ok {
+			return v + "@" + domain
+		}
+		if slug, ok := e.ListSlug(); ok {
+			return v + "/" + slug
+		}
+		return v
+	}
+	if v, ok := e.Hashtag(); ok {
+		return v
+	}
+	if v, ok := e.Symbol(); ok {
+		return v
+	}
+	if v, ok := e.NormalizedURL(); ok {
+		return v
+	}
+	return ""
+}
+
+// splitList splits a comma separated flag value. Returns nil for an empty
+// value
+func splitList(value string) []string {
+	if value == "" {
+		return nil
+	}
+	list := strings.Split(value, ",")
+	for i := range list {
+		list[i] = strings.TrimSpace(list[i])
+	}
+	return list
+}
+
+type validateResult struct {
+	Valid bool   `json:"valid"`
+	Error string `json:"error,omitempty"`
+}
+
+func runValidate(c *context) int {
+	maxLength := c.flags.Int("maxLength", validate.DefaultParseConfig().MaxWeightedLength, "the maximum length of the text")
+	canBeEmpty := c.flags.Bool("canBeEmpty", false, "accept an empty text")
+	isURL := c.flags.Bool("url", false, "validate the text as a single URL instead")
+	requireProtocol := c.flags.Bool("requireProtocol", false, "with -url, require an http:// or https:// prefix")
+	allowUnicode := c.flags.Bool("allowUnicode", false, "with -url, accept Unicode domain names")
+	checkASCIIHost := c.flags.Bool("checkASCIIHost", false, "with -url, check the length and labels of the punycode host")
+	rejectSpoofedHost := c.flags.Bool("rejectSpoofedHost", false, "with -url, reject mixed-script and confusable hosts")
+	protectedDomains := c.flags.String("protectedDomains", "", "with -rejectSpoofedHost, comma separated domains whose lookalikes are rejected")
+	text, err := c.parse()
+	if err != nil {
+		return c.fail(err)
+	}
+
+	if *isURL {
+		_, err = validate.ParseURL(text, validate.URLArgs{
+			RequireProtocol:   *requireProtocol,
+			AllowUnicode:      *allowUnicode,
+			CheckASCIIHost:    *checkASCIIHost,
+			RejectSpoofedHost: *rejectSpoofedHost,
+			ProtectedDomains:  splitList(*protectedDomains),
+		})
+	} else {
+		err = validate.TextValidate(text, validate.ValidationArgs{
+			MaxLength:  *maxLength,
+			CanBeEmpty: *canBeEmpty,
+		})
+	}
+	return c.printValidity(err)
+}
+
+// printValidity prints the result of a validation and returns the exit
+// status for it
+func (c *context) printValidity(validationErr error) int {
+	result := validateResult{Valid: validationErr == nil}
+	if validationErr != nil {
+		result.Error = validationErr.Error()
+	}
+
+	var err error
+	if *c.json {
+		err = c.printJSON(result)
+	} else if result.Valid {
+		_, err = fmt.Fprintln(c.stdout, "valid")
+	} else {
+		_, err = fmt.Fprintf(c.stdout, "invalid: %s\n", result.Error)
+	}
+	if err != nil {
+		return c.fail(err)
+	}
+	if !result.Valid {
+		return exitInvalid
+	}
+	return exitOK
+}
+
+type lengthResult struct {
+	Length         int  `json:"length"`
+	WeightedLength int  `json:"weighted_length"`
+	Permillage     int  `json:"permillage"`
+	Valid          bool `json:"valid"`
+}
+
+func runLength(c *context) int {
+	maxLength := c.flags.Int("maxLength", validate.DefaultParseConfig().MaxWeightedLength, "the maximum weighted length of the text")
+	text, err := c.parse()
+	if err != nil {
+		return c.fail(err)
+	}
+
+	config := validate.DefaultParseConfig()
+	config.MaxWeightedLength = *maxLength
+	parsed := validate.ParseText(text, config)
+	result := lengthResult{
+		Length:         validate.TextLength(text),
+		WeightedLength: parsed.WeightedLength,
+		Permillage:     parsed.Permillage,
+		Valid:          parsed.IsValid,
+	}
+
+	if *c.json {
+		err = c.printJSON(result)
+	} else {
+		err = c.printTable([][]string{
+			{"length", fmt.Sprint(result.Length)},
+			{"weighted length", fmt.Sprint(result.WeightedLength)},
+			{"permillage", fmt.Sprint(result.Permillage)},
+			{"valid", fmt.Sprint(result.Valid)},
+		})
+	}
+	if err != nil {
+		return c.fail(err)
+	}
+	return exitOK
+}
+
+func runUsername(c *context) int {
+	text, err := c.parse()
+	if err != nil {
+		return c.fail(err)
+	}
+
+	return c.printValidity(validate.UsernameValidate(text))
+}
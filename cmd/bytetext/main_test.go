@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		description string
+		args        []string
+		stdin       string
+		status      int
+		stdout      string
+	}{
+		{
+			description: "extract table",
+			args:        []string{"extract", "hi", "@user", "#tag"},
+			status:      exitOK,
+			stdout: "TYPE     TEXT   VALUE  CHARS    BYTES    UTF16\n" +
+				"Mention  @user  user   (3, 8)   (3, 8)   (3, 8)\n" +
+				"Hashtag  #tag   tag    (9, 13)  (9, 13)  (9, 13)\n",
+		},
+		{
+			description: "extract JSON from stdin",
+			args:        []string{"extract", "-json", "-types", "Cashtag"},
+			stdin:       "@user $TSLA\n",
+			status:      exitOK,
+			stdout: `[
+  {
+    "type": "Cashtag",
+    "text": "$TSLA",
+    "indices": [
+      6,
+      11
+    ],
+    "byte_indices": [
+      6,
+      11
+    ],
+    "utf16_indices": [
+      6,
+      11
+    ],
+    "symbol": "TSLA"
+  }
+]
+`,
+		},
+		{
+			description: "extract without protocol-less URLs",
+			args:        []string{"extract", "-json", "-requireProtocol", "example.com"},
+			status:      exitOK,
+			stdout:      "[]\n",
+		},
+		{
+			description: "extract federated mentions",
+			args:        []string{"extract", "-federatedMentions", "@alice@mastodon.social"},
+			status:      exitOK,
+			stdout: "TYPE     TEXT                    VALUE                  CHARS    BYTES    UTF16\n" +
+				"Mention  @alice@mastodon.social  alice@mastodon.social  (0, 22)  (0, 22)  (0, 22)\n",
+		},
+		{
+			description: "extract with an unknown type",
+			args:        []string{"extract", "-types", "Emoji", "text"},
+			status:      exitUsage,
+		},
+		{
+			description: "valid text",
+			args:        []string{"validate", "hello"},
+			status:      exitOK,
+			stdout:      "valid\n",
+		},
+		{
+			description: "text too long",
+			args:        []string{"validate", "-maxLength", "3", "hello"},
+			status:      exitInvalid,
+			stdout:      "invalid: Length 5 exceeds 3 characters\n",
+		},
+		{
+			description: "empty text from stdin",
+			args:        []string{"validate", "-json"},
+			stdin:       "\n",
+			status:      exitInvalid,
+			stdout:      "{\n  \"valid\": false,\n  \"error\": \"Text may not be empty\"\n}\n",
+		},
+		{
+			description: "URL without a required protocol",
+			args:        []string{"validate", "-url", "-requireProtocol", "example.com"},
+			status:      exitInvalid,
+			stdout:      "invalid: Invalid URL scheme []\n",
+		},
+		{
+			description: "Unicode URL",
+			args:        []string{"validate", "-url", "-allowUnicode", "http://☃.net/"},
+			status:      exitOK,
+			stdout:      "valid\n",
+		},
+		{
+			description: "Unicode URL with a long punycode host",
+			args:        []string{"validate", "-url", "-allowUnicode", "-checkASCIIHost", "http://" + strings.Repeat("ü", 40) + strings.Repeat("b", 20) + ".de/"},
+			status:      exitInvalid,
+			stdout:      "invalid: Invalid URL host [" + strings.Repeat("ü", 40) + strings.Repeat("b", 20) + ".de]\n",
+		},
+		{
+			description: "URL lookalike of a protected domain",
+			args:        []string{"validate", "-url", "-rejectSpoofedHost", "-protectedDomains", "paypal.com, apple.com", "http://paypa1.com/"},
+			status:      exitInvalid,
+			stdout:      "invalid: URL host [paypa1.com] looks like [paypal.com]\n",
+		},
+		{
+			description: "length",
+			args:        []string{"length", "-json", "日本"},
+			status:      exitOK,
+			stdout: `{
+  "length": 2,
+  "weighted_length": 4,
+  "permillage": 14,
+  "valid": true
+}
+`,
+		},
+		{
+			description: "valid username",
+			args:        []string{"username", "@jack"},
+			status:      exitOK,
+			stdout:      "valid\n",
+		},
+		{
+			description: "invalid username",
+			args:        []string{"username", "jack"},
+			status:      exitInvalid,
+			stdout:      "invalid: Text must start with [@]\n",
+		},
+		{
+			description: "unknown command",
+			args:        []string{"frobnicate"},
+			status:      exitUsage,
+		},
+	}
+
+	for _, test := range tests {
+		var stdout, stderr bytes.Buffer
+		status := run(test.args, strings.NewReader(test.stdin), &stdout, &stderr)
+		if status != test.status {
+			t.Errorf(
+				"run returned incorrect status for test [%s]. Expected:%v Got:%v (stderr: %s)",
+				test.description,
+				test.status,
+				status,
+				stderr.String(),
+			)
+		}
+		if stdout.String() != test.stdout {
+			t.Errorf(
+				"run printed incorrect output for test [%s]. Expected:%q Got:%q",
+				test.description,
+				test.stdout,
+				stdout.String(),
+			)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+// Command tldgen regenerates the built-in TLD registry of the extract package
+// and the TLD conformance tests from the IANA list of TLDs.
+//
+// Usage:
+//
+//	tldgen [flags] tlds-alpha-by-domain.txt
+//
+// The flags are:
+//
+//	-go path           the Go file of the registry (default extract/tld_data.go)
+//	-conformance path  the conformance file (default conformance/tlds.yml)
+//
+// Run it from the root of the repository, with a copy of
+// https://data.iana.org/TLD/tlds-alpha-by-domain.txt. The special-use TLDs
+// which are not delegated in the root zone, such as "onion", are added to
+// the generic TLDs.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/interspace/byte-text-go/extract"
+)
+
+// Special-use domain names (See: RFC 6761) which are used in URLs although
+// they are not in the IANA list
+var specialUseTLDs = []string{
+	"onion", // RFC 7686
+}
+
+func main() {
+	flags := flag.NewFlagSet("tldgen", flag.ContinueOnError)
+	goPath := flags.String("go", "extract/tld_data.go", "the Go file of the registry")
+	conformancePath := flags.String("conformance", "conformance/tlds.yml", "the conformance file")
+	if err := flags.Parse(os.Args[1:]); err != nil {
+		os.Exit(2)
+	}
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: tldgen [flags] tlds-alpha-by-domain.txt")
+		flags.PrintDefaults()
+		os.Exit(2)
+	}
+
+	if err := run(flags.Arg(0), *goPath, *conformancePath); err != nil {
+		fmt.Fprintf(os.Stderr, "tldgen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(listPath, goPath, conformancePath string) error {
+	list, err := ioutil.ReadFile(listPath)
+	if err != nil {
+		return err
+	}
+	registry, err := parseList(list)
+	if err != nil {
+		return err
+	}
+
+	var source, conformance bytes.Buffer
+	if err := writeGo(&source, registry, listVersion(list)); err != nil {
+		return err
+	}
+	writeConformance(&conformance, registry)
+
+	if err := ioutil.WriteFile(goPath, source.Bytes(), 0644); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(conformancePath, conformance.Bytes(), 0644)
+}
+
+// parseList parses the IANA list and adds the special-use TLDs
+func parseList(list []byte) (*extract.TLDRegistry, error) {
+	parsed, err := extract.ParseTLDRegistry(bytes.NewReader(list))
+	if err != nil {
+		return nil, err
+	}
+	generic := append(parsed.GenericTLDs(), specialUseTLDs...)
+	return extract.NewTLDRegistry(generic, parsed.CountryTLDs())
+}
+
+// listVersion returns the version comment at the top of the IANA list, e.g.
+// "Version 2019062000, Last Updated Thu Jun 20 07:07:01 2019 UTC", or an
+// empty string if there is none
+func listVersion(list []byte) string {
+	line := list
+	if i := bytes.IndexByte(list, '\n'); i >= 0 {
+		line = list[:i]
+	}
+	if !bytes.HasPrefix(line, []byte("#")) {
+		return ""
+	}
+	return strings.TrimSpace(string(line[1:]))
+}
+
+// writeGo writes the Go source of the registry's lists
+func writeGo(w io.Writer, registry *extract.TLDRegistry, version string) error {
+	var b bytes.Buffer
+	b.WriteString("// Code generated by tldgen from the IANA list of TLDs. DO NOT EDIT.\n")
+	if version != "" {
+		b.WriteString("// " + version + "\n")
+	}
+	b.WriteString("\npackage extract\n")
+	writeGoList(&b, "The generic TLDs of the built-in registry", "genericTLDs", registry.GenericTLDs())
+	writeGoList(&b, "The country code TLDs of the built-in registry", "countryTLDs", registry.CountryTLDs())
+
+	source, err := format.Source(b.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(source)
+	return err
+}
+
+// writeGoList writes a string slice variable, wrapping its elements at about
+// 100 columns
+func writeGoList(b *bytes.Buffer, doc, name string, tlds []string) {
+	fmt.Fprintf(b, "\n// %s\nvar %s = []string{\n", doc, name)
+	width := 0
+	for _, tld := range tlds {
+		quoted := fmt.Sprintf("%q,", tld)
+		n := utf8.RuneCountInString(quoted)
+		if width > 0 && width+1+n > 96 {
+			b.WriteString("\n")
+			width = 0
+		}
+		if width > 0 {
+			b.WriteString(" ")
+			width++
+		}
+		b.WriteString(quoted)
+		width += n
+	}
+	b.WriteString("\n}\n")
+}
+
+// writeConformance writes a test of a URL ending in each TLD
+func writeConformance(w io.Writer, registry *extract.TLDRegistry) {
+	fmt.Fprint(w, "---\ntests:\n")
+	for _, kind := range []struct {
+		name string
+		tlds []string
+	}{
+		{"country", registry.CountryTLDs()},
+		{"generic", registry.GenericTLDs()},
+	} {
+		fmt.Fprintf(w, "  %s:\n", kind.name)
+		for _, tld := range kind.tlds {
+			description := fmt.Sprintf("%s is a valid %s tld", tld, kind.name)
+			if r, _ := utf8.DecodeRuneInString(tld); r >= utf8.RuneSelf {
+				description = `"` + description + `"`
+			}
+			url := "https://twitter." + tld
+			fmt.Fprintf(w, "  - description: %s\n    text: %s\n    expected:\n    - %s\n",
+				description, url, url)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tldgen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	listPath := filepath.Join(dir, "tlds-alpha-by-domain.txt")
+	goPath := filepath.Join(dir, "tld_data.go")
+	conformancePath := filepath.Join(dir, "tlds.yml")
+	list := "# Version 2019062000, Last Updated Thu Jun 20 07:07:01 2019 UTC\nCOM\nJP\nXN--P1AI\n"
+	if err := ioutil.WriteFile(listPath, []byte(list), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := run(listPath, goPath, conformancePath); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	source, err := ioutil.ReadFile(goPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedSource := `// Code generated by tldgen from the IANA list of TLDs. DO NOT EDIT.
+// Version 2019062000, Last Updated Thu Jun 20 07:07:01 2019 UTC
+
+package extract
+
+// The generic TLDs of the built-in registry
+var genericTLDs = []string{
+	"com", "onion",
+}
+
+// The country code TLDs of the built-in registry
+var countryTLDs = []string{
+	"jp", "рф",
+}
+`
+	if string(source) != expectedSource {
+		t.Errorf("Wrong Go source. Expected:\n%s\nGot:\n%s", expectedSource, source)
+	}
+
+	conformance, err := ioutil.ReadFile(conformancePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"  country:\n  - description: jp is a valid country tld\n    text: https://twitter.jp\n",
+		"  - description: \"рф is a valid country tld\"\n    text: https://twitter.рф\n",
+		"  generic:\n  - description: com is a valid generic tld\n",
+		"    expected:\n    - https://twitter.onion\n",
+	} {
+		if !strings.Contains(string(conformance), expected) {
+			t.Errorf("Conformance file does not contain %q:\n%s", expected, conformance)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package extract
+
+import "testing"
+
+// Typical texts, mixing entities of all types with plain text in several
+// scripts
+var benchmarkTexts = []string{
+	"Just setting up my account",
+	"RT @user: Check out https://example.com/path?query=1 #golang $GOOG",
+	"@alice @bob_smith thanks for the link example.org/blog/2019/(draft) :)",
+	"今日は良い天気ですね #日本 http://t.co/abcdef ＠ユーザー",
+	"Ce café est très bon, voir www.café.fr/menu ou #café $BRK.A",
+	"No entities here, only a fairly long sentence written to look like an ordinary post without any links.",
+	"Multiple urls: foo.com, bar.co.uk/x, https://baz.io:8080/a/b?c=d&e=f#frag and #tags #more #evenmore",
+}
+
+func benchmarkEntities(b *testing.B, entities func(string) []*ByteEntity) {
+	for i := 0; i < b.N; i++ {
+		for _, text := range benchmarkTexts {
+			entities(text)
+		}
+	}
+}
+
+func BenchmarkEntities(b *testing.B) {
+	benchmarkEntities(b, defaultExtractor.Entities)
+}
+
+func BenchmarkReferenceEntities(b *testing.B) {
+	x, err := newReferenceExtractor(DefaultExtractorOptions())
+	if err != nil {
+		b.Fatal(err)
+	}
+	benchmarkEntities(b, x.Entities)
+}
+
+func BenchmarkURLs(b *testing.B) {
+	benchmarkEntities(b, defaultExtractor.URLs)
+}
+
+func BenchmarkReferenceURLs(b *testing.B) {
+	x, err := newReferenceExtractor(DefaultExtractorOptions())
+	if err != nil {
+		b.Fatal(err)
+	}
+	benchmarkEntities(b, x.URLs)
+}
+
+func BenchmarkHashtags(b *testing.B) {
+	benchmarkEntities(b, defaultExtractor.Hashtags)
+}
+
+func BenchmarkReferenceHashtags(b *testing.B) {
+	x, err := newReferenceExtractor(DefaultExtractorOptions())
+	if err != nil {
+		b.Fatal(err)
+	}
+	benchmarkEntities(b, x.Hashtags)
+}
@@ -0,0 +1,246 @@
+package extract
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// The character classes of the extraction grammar. Classes of the URL,
+// mention and cashtag rules are case-insensitive: they also contain the case
+// variants of their letters (See: inFoldedClass), such as the Kelvin sign
+// "K" for "k" or the long s "ſ" for "s"
+
+const (
+	atSignChars = "@\uff20"
+
+	hashtagSpecialChars = "_" +
+		"\u200c" + // ZERO WIDTH NON-JOINER (ZWNJ)
+		"\u200d" + // ZERO WIDTH JOINER (ZWJ)
+		"\ua67e" + // CYRILLIC KAVYKA
+		"\u05be" + // HEBREW PUNCTUATION MAQAF
+		"\u05f3" + // HEBREW PUNCTUATION GERESH
+		"\u05f4" + // HEBREW PUNCTUATION GERSHAYIM
+		"\u309b" + // KATAKANA-HIRAGANA VOICED SOUND MARK
+		"\u309c" + // KATAKANA-HIRAGANA SEMI-VOICED SOUND MARK
+		"\u30a0" + // KATAKANA-HIRAGANA DOUBLE HYPHEN
+		"\u30fb" + // KATAKANA MIDDLE DOT
+		"\u3003" + // DITTO MARK
+		"\u0f0b" + // TIBETAN MARK INTERSYLLABIC TSHEG
+		"\u0f0c" + // TIBETAN MARK DELIMITER TSHEG BSTAR
+		"\u00b7" // MIDDLE DOT
+
+	defaultUsernameSeparators = "_.~"
+
+	kelvinSign = '\u212a'
+	longS      = '\u017f'
+)
+
+// asciiSet is a set of ASCII characters
+type asciiSet [utf8.RuneSelf]bool
+
+func newASCIISet(chars ...string) *asciiSet {
+	var set asciiSet
+	for _, s := range chars {
+		for i := 0; i < len(s); i++ {
+			set[s[i]] = true
+		}
+	}
+	return &set
+}
+
+func (s *asciiSet) has(r rune) bool {
+	return r >= 0 && r < utf8.RuneSelf && s[r]
+}
+
+const (
+	asciiDigits  = "0123456789"
+	asciiLetters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+)
+
+var (
+	alnumChars = newASCIISet(asciiDigits, asciiLetters)
+
+	// ASCII punctuation, except the backslash
+	punctuationChars = newASCIISet("!\"#$%&'()*+,-./:;<=>?@[]^_`{|}~")
+
+	// Characters which may not precede an @mention
+	invalidMentionPrecedingChars = newASCIISet(asciiDigits, asciiLetters, "_!#$%&*@")
+
+	urlPathChars        = newASCIISet(asciiDigits, asciiLetters, "!*';:=+,.$/%#[]-_~|&@")
+	urlPathEndingChars  = newASCIISet(asciiDigits, asciiLetters, "=_#/-+")
+	urlQueryChars       = newASCIISet(asciiDigits, asciiLetters, "!?*'();:&=+$/%#[]-_.,~|@")
+	urlQueryEndingChars = newASCIISet(asciiDigits, asciiLetters, "_&=#/")
+)
+
+// inFoldedClass reports whether r or one of its case variants is in the
+// class. The ASCII part of a class must contain both cases of its letters,
+// and the non-ASCII case variants of ASCII letters must be handled by in
+func inFoldedClass(r rune, in func(rune) bool) bool {
+	if in(r) {
+		return true
+	}
+	if r < utf8.RuneSelf {
+		return false
+	}
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		if in(f) {
+			return true
+		}
+	}
+	return false
+}
+
+// isAlnum reports whether r is an ASCII letter or digit, or a non-ASCII case
+// variant of an ASCII letter
+func isAlnum(r rune) bool {
+	return alnumChars.has(r) || r == kelvinSign || r == longS
+}
+
+// isASCIIAlnum reports whether r is an ASCII letter or digit, for the rules
+// which are case-sensitive
+func isASCIIAlnum(r rune) bool {
+	return alnumChars.has(r)
+}
+
+func isASCIILetter(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+}
+
+// isLetter reports whether r is an ASCII letter or a non-ASCII case variant
+// of one
+func isLetter(r rune) bool {
+	return isAlnum(r) && (r < '0' || r > '9')
+}
+
+// IsSpace reports whether r is whitespace to the extraction rules, e.g. the
+// whitespace which may separate reply mentions (See: ReplyMentions). Unlike
+// unicode.IsSpace, it includes U+180E MONGOLIAN VOWEL SEPARATOR
+func IsSpace(r rune) bool {
+	return isUnicodeSpace(r)
+}
+
+func isUnicodeSpace(r rune) bool {
+	switch {
+	case r >= 0x0009 && r <= 0x000d, // White_Space # Cc   [5] <control-0009>..<control-000D>
+		r == 0x0020,                // White_Space # Zs       SPACE
+		r == 0x0085,                // White_Space # Cc       <control-0085>
+		r == 0x00a0,                // White_Space # Zs       NO-BREAK SPACE
+		r == 0x1680,                // White_Space # Zs       OGHAM SPACE MARK
+		r == 0x180e,                // White_Space # Zs       MONGOLIAN VOWEL SEPARATOR
+		r >= 0x2000 && r <= 0x200a, // White_Space # Zs  [11] EN QUAD..HAIR SPACE
+		r == 0x2028,                // White_Space # Zl       LINE SEPARATOR
+		r == 0x2029,                // White_Space # Zp       PARAGRAPH SEPARATOR
+		r == 0x202f,                // White_Space # Zs       NARROW NO-BREAK SPACE
+		r == 0x205f,                // White_Space # Zs       MEDIUM MATHEMATICAL SPACE
+		r == 0x3000:                // White_Space # Zs       IDEOGRAPHIC SPACE
+		return true
+	}
+	return false
+}
+
+// isDirectionalFormatting reports whether r is one of the embedding and
+// override characters U+202A to U+202E
+func isDirectionalFormatting(r rune) bool {
+	return r >= 0x202a && r <= 0x202e
+}
+
+func isLatinAccent(r rune) bool {
+	switch {
+	case r >= 0x00c0 && r <= 0x00d6, r >= 0x00d8 && r <= 0x00f6, r >= 0x00f8 && r <= 0x00ff: // Latin-1
+		return true
+	case r >= 0x0100 && r <= 0x024f: // Latin Extended A and B
+		return true
+	case r == 0x0253, r == 0x0254, r == 0x0256, r == 0x0257, r == 0x0259, r == 0x025b,
+		r == 0x0263, r == 0x0268, r == 0x026f, r == 0x0272, r == 0x0289, r == 0x028b: // IPA Extensions
+		return true
+	case r == 0x02bb: // Hawaiian
+		return true
+	case r >= 0x0300 && r <= 0x036f: // Combining diacritics
+		return true
+	case r >= 0x1e00 && r <= 0x1eff: // Latin Extended Additional (mostly for Vietnamese)
+		return true
+	}
+	return false
+}
+
+// isURLChar reports whether r may appear in a domain label: anything but
+// punctuation, spaces, control characters and the invalid characters
+func isURLChar(r rune) bool {
+	if r < utf8.RuneSelf {
+		return r > 0x20 && r != 0x7f && !punctuationChars.has(r)
+	}
+	switch r {
+	case 0xfffe, 0xfeff, 0xffff:
+		return false
+	}
+	return !isDirectionalFormatting(r) && !isUnicodeSpace(r)
+}
+
+// isURLPrecedingChar reports whether r may precede a URL
+func isURLPrecedingChar(r rune) bool {
+	switch r {
+	case '@', '＠', '$', '#', '＃':
+		return false
+	}
+	return !isAlnum(r) && !isDirectionalFormatting(r)
+}
+
+func isURLPathChar(r rune) bool {
+	if r < utf8.RuneSelf {
+		return urlPathChars.has(r)
+	}
+	return r == kelvinSign || inFoldedClass(r, isLatinAccent)
+}
+
+func isURLPathEndingChar(r rune) bool {
+	if r < utf8.RuneSelf {
+		return urlPathEndingChars.has(r)
+	}
+	return r == kelvinSign || inFoldedClass(r, isLatinAccent)
+}
+
+func isURLQueryChar(r rune) bool {
+	return urlQueryChars.has(r) || r == kelvinSign || r == longS
+}
+
+func isURLQueryEndingChar(r rune) bool {
+	return urlQueryEndingChars.has(r) || r == kelvinSign || r == longS
+}
+
+// IsUsernameCharacter reports whether r may appear in a username, apart
+// from the separators: an ASCII letter or digit, or the Kelvin sign or long s,
+// which case fold to "k" and "s"
+func IsUsernameCharacter(r rune) bool {
+	return isAlnum(r)
+}
+
+// IsHashtagCharacter reports whether r may appear in a hashtag. A hashtag
+// must also contain at least one letter (a character in category L or M)
+func IsHashtagCharacter(r rune) bool {
+	if r < utf8.RuneSelf {
+		return alnumChars.has(r) || r == '_'
+	}
+	return isHashtagAlpha(r) || unicode.Is(unicode.Nd, r) ||
+		strings.ContainsRune(hashtagSpecialChars, r)
+}
+
+func isHashtagAlpha(r rune) bool {
+	if r < utf8.RuneSelf {
+		return alnumChars.has(r) && (r < '0' || r > '9')
+	}
+	return unicode.In(r, unicode.L, unicode.M)
+}
+
+// isMentionPrecedingChar reports whether r may precede an @mention
+func isMentionPrecedingChar(r rune) bool {
+	return !invalidMentionPrecedingChars.has(r) && !isAlnum(r) && r != '＠'
+}
+
+func isAtSign(r rune) bool {
+	return r == '@' || r == '＠'
+}
+
+func isHashSign(r rune) bool {
+	return r == '#' || r == '＃'
+}
@@ -0,0 +1,123 @@
+package extract
+
+import (
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// confusables maps characters to the prototype they are visually confusable
+// with. It is the subset of the UTS #39 confusables.txt data
+// (See: http://www.unicode.org/Public/security/latest/confusables.txt)
+// covering the Latin, Greek, Cyrillic and Armenian lookalikes of ASCII
+// letters and digits, which are the ones used to spoof domains and usernames
+// in practice. Fullwidth forms are handled by skeletonRune
+var confusables = map[rune]rune{
+	// ASCII and Latin
+	'0':    'O',
+	'1':    'l',
+	'I':    'l',
+	'|':    'l',
+	0x0131: 'i', // ı LATIN SMALL LETTER DOTLESS I
+	0x0251: 'a', // ɑ LATIN SMALL LETTER ALPHA
+	0x0261: 'g', // ɡ LATIN SMALL LETTER SCRIPT G
+	0x01C0: 'l', // ǀ LATIN LETTER DENTAL CLICK
+	0x2170: 'i', // ⅰ SMALL ROMAN NUMERAL ONE
+	0x217C: 'l', // ⅼ SMALL ROMAN NUMERAL FIFTY
+
+	// Greek
+	0x0391: 'A', // Α
+	0x0392: 'B', // Β
+	0x0395: 'E', // Ε
+	0x0396: 'Z', // Ζ
+	0x0397: 'H', // Η
+	0x0399: 'l', // Ι
+	0x039A: 'K', // Κ
+	0x039C: 'M', // Μ
+	0x039D: 'N', // Ν
+	0x039F: 'O', // Ο
+	0x03A1: 'P', // Ρ
+	0x03A4: 'T', // Τ
+	0x03A5: 'Y', // Υ
+	0x03A7: 'X', // Χ
+	0x03B1: 'a', // α
+	0x03B3: 'y', // γ
+	0x03B9: 'i', // ι
+	0x03BD: 'v', // ν
+	0x03BF: 'o', // ο
+	0x03C1: 'p', // ρ
+
+	// Cyrillic
+	0x0405: 'S', // Ѕ
+	0x0406: 'l', // І
+	0x0408: 'J', // Ј
+	0x0410: 'A', // А
+	0x0412: 'B', // В
+	0x0415: 'E', // Е
+	0x0417: '3', // З
+	0x041A: 'K', // К
+	0x041C: 'M', // М
+	0x041D: 'H', // Н
+	0x041E: 'O', // О
+	0x0420: 'P', // Р
+	0x0421: 'C', // С
+	0x0422: 'T', // Т
+	0x0425: 'X', // Х
+	0x0430: 'a', // а
+	0x0435: 'e', // е
+	0x043E: 'o', // о
+	0x0440: 'p', // р
+	0x0441: 'c', // с
+	0x0443: 'y', // у
+	0x0445: 'x', // х
+	0x0455: 's', // ѕ
+	0x0456: 'i', // і
+	0x0458: 'j', // ј
+	0x0475: 'v', // ѵ
+	0x04AE: 'Y', // Ү
+	0x04AF: 'y', // ү
+	0x04BB: 'h', // һ
+	0x04C0: 'l', // Ӏ
+	0x04CF: 'l', // ӏ
+	0x0501: 'd', // ԁ
+	0x051A: 'Q', // Ԛ
+	0x051B: 'q', // ԛ
+	0x051C: 'W', // Ԝ
+	0x051D: 'w', // ԝ
+
+	// Armenian
+	0x054D: 'U', // Ս
+	0x0555: 'O', // Օ
+	0x0570: 'h', // հ
+	0x0578: 'n', // ո
+	0x057D: 'u', // ս
+	0x0581: 'g', // ց
+	0x0585: 'o', // օ
+}
+
+// Skeleton returns the UTS #39 skeleton of s
+// (See: http://www.unicode.org/reports/tr39/#Confusable_Detection): the
+// string is decomposed and each character replaced by its prototype, so that
+// two strings which look alike have the same skeleton, e.g. "pаypal" with a
+// Cyrillic "а" and "paypal". Skeletons are only meant to be compared with
+// each other, not displayed
+func Skeleton(s string) string {
+	s = norm.NFD.String(s)
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		b.WriteRune(skeletonRune(r))
+	}
+	return norm.NFD.String(b.String())
+}
+
+func skeletonRune(r rune) rune {
+	// Fullwidth ASCII variants look like their ASCII counterpart
+	if r >= 0xFF01 && r <= 0xFF5E {
+		r -= 0xFEE0
+	}
+	if prototype, ok := confusables[r]; ok {
+		return prototype
+	}
+	return r
+}
@@ -1,12 +1,12 @@
 // Package extract provides a set of routines for extracting various Byte
 // "entities" from text
 //
-// This package supports extraction of Byte usernames, hashtags, and URLs.
+// This package supports extraction of Byte usernames, hashtags, cashtags, and
+// URLs.
 package extract
 
 import (
 	"fmt"
-	"sort"
 	"strings"
 	"unicode/utf8"
 )
@@ -35,6 +35,8 @@ const (
 	Mention EntityType = iota
 	Hashtag
 	URL
+	Cashtag
+	List
 )
 
 // String implements the Stringer interface
@@ -46,28 +48,132 @@ func (t EntityType) String() string {
 		return "Hashtag"
 	case URL:
 		return "URL"
+	case Cashtag:
+		return "Cashtag"
+	case List:
+		return "List"
 	}
 	return "Unknown"
 }
 
 // ByteEntity is the structure representing an "entity" within a string.
 type ByteEntity struct {
-	Text      string // The value of the entity
-	Range     Range  // Represents the location of the entity in character/rune offsets
-	ByteRange Range  // Represents the location of the entity in byte offsets
-	Type      EntityType
+	Text       string // The value of the entity
+	Range      Range  // Represents the location of the entity in character/rune offsets
+	ByteRange  Range  // Represents the location of the entity in byte offsets
+	UTF16Range Range  // Represents the location of the entity in UTF-16 code unit offsets
+	Type       EntityType
+
+	screenName    string // Contains the value of username without the leading '@' when Type=Mention
+	screenNameKey string // Contains the canonical key of the username when Type=Mention
+	mentionDomain string // Contains the domain of a federated mention when Type=Mention
+	listSlug      string // Contains the slug of the list without the leading '/' when Type=List
+	hashtag       string // Contains the value of the hashtag without the leading # when Type=Hashtag
+	hashtagKey    string // Contains the canonical key of the hashtag when Type=Hashtag
+	cashtag       string // Contains the value of the symbol without the leading $ when Type=Cashtag
+	urlParts      URLParts
+
+	// The spoofing analysis of the host when Type=URL and the host is a valid
+	// internationalized domain name
+	hostSpoofing HostSpoofing
+
+	screenNameIsSet    bool
+	screenNameKeyIsSet bool
+	mentionDomainIsSet bool
+	listSlugIsSet      bool
+	hashtagIsSet       bool
+	hashtagKeyIsSet    bool
+	cashtagIsSet       bool
+	urlPartsIsSet      bool
+	hostSpoofingIsSet  bool
+}
+
+// URLParts holds the components of a URL entity. Components which are not
+// present in the URL are empty.
+type URLParts struct {
+	Scheme   string `json:"scheme,omitempty"` // The protocol without "://"
+	Host     string `json:"host"`
+	Port     string `json:"port,omitempty"`
+	Path     string `json:"path,omitempty"`     // Includes the leading '/'
+	Query    string `json:"query,omitempty"`    // Without the leading '?'
+	Fragment string `json:"fragment,omitempty"` // Without the leading '#'
+
+	// The host converted by HostToASCII and HostToUnicode. Both are empty
+	// when the host is not a valid internationalized domain name
+	ASCIIHost   string `json:"ascii_host,omitempty"`
+	UnicodeHost string `json:"unicode_host,omitempty"`
+}
 
-	screenName string // Contains the value of username without the leading '@' when Type=Mention
-	hashtag    string // Contains the value of the hashtag without the leading # when Type=Hashtag
+// newURLParts builds the parts of a URL from the byte ranges of its
+// components within s. A component whose start is negative is absent
+func newURLParts(s string, protocol, domain, port, path, query Range) URLParts {
+	component := func(r Range) string {
+		if r.Start < 0 || r.Stop <= r.Start {
+			return ""
+		}
+		return s[r.Start:r.Stop]
+	}
+
+	parts := URLParts{
+		Scheme: strings.TrimSuffix(component(protocol), "://"),
+		Host:   component(domain),
+		Port:   component(port),
+		Path:   component(path),
+		Query:  strings.TrimPrefix(component(query), "?"),
+	}
 
-	screenNameIsSet bool
-	hashtagIsSet    bool
+	// The path and query patterns accept '#', so split off the fragment
+	if i := strings.IndexByte(parts.Path, '#'); i >= 0 {
+		parts.Fragment = parts.Path[i+1:]
+		parts.Path = parts.Path[:i]
+		if parts.Query != "" {
+			parts.Fragment += "?" + parts.Query
+			parts.Query = ""
+		}
+	} else if i := strings.IndexByte(parts.Query, '#'); i >= 0 {
+		parts.Fragment = parts.Query[i+1:]
+		parts.Query = parts.Query[:i]
+	}
+
+	if ascii, err := HostToASCII(parts.Host); err == nil {
+		if unicode, err := HostToUnicode(ascii); err == nil {
+			parts.ASCIIHost, parts.UnicodeHost = ascii, unicode
+		}
+	}
+	return parts
+}
+
+// String reassembles the parts into a URL, using http when the scheme is
+// missing
+func (p URLParts) String() string {
+	var b strings.Builder
+	if p.Scheme != "" {
+		b.WriteString(strings.ToLower(p.Scheme))
+	} else {
+		b.WriteString("http")
+	}
+	b.WriteString("://")
+	b.WriteString(strings.ToLower(p.Host))
+	if p.Port != "" {
+		b.WriteString(":")
+		b.WriteString(p.Port)
+	}
+	b.WriteString(p.Path)
+	if p.Query != "" {
+		b.WriteString("?")
+		b.WriteString(p.Query)
+	}
+	if p.Fragment != "" {
+		b.WriteString("#")
+		b.WriteString(p.Fragment)
+	}
+	return b.String()
 }
 
 type entitiesT []*ByteEntity
 
 // The indices generated by the various extract functions are
-// byte offsets. This function calculates charecter/rune offsets
+// byte offsets. This function calculates charecter/rune and UTF-16 offsets
 // based on those offsets
 func (e entitiesT) fixIndices(text string) {
 	for _, entity := range e {
@@ -75,37 +181,24 @@ func (e entitiesT) fixIndices(text string) {
 		entity.Range.Start = start
 		stop := utf8.RuneCountInString(entity.Text)
 		entity.Range.Stop = start + stop
-	}
-}
-
-func (e *entitiesT) removeOverlappingEntities() {
-	if e.Len() < 2 {
-		return
-	}
 
-	var (
-		result   []*ByteEntity
-		prev     *ByteEntity
-		cur      *ByteEntity
-		entities = *e
-	)
-	prev = entities[0]
-	result = append(result, prev)
-	for _, cur = range entities[1:] {
-		if !(prev.Range.Stop > cur.Range.Start) {
-			result = append(result, cur)
-		}
-		prev = cur
+		start = utf16Len(text[:entity.ByteRange.Start])
+		entity.UTF16Range.Start = start
+		entity.UTF16Range.Stop = start + utf16Len(entity.Text)
 	}
-	*e = result
 }
 
 func (e entitiesT) Len() int {
 	return len(e)
 }
 
+// Less orders entities by start, and longer entities first when they start
+// at the same offset
 func (e entitiesT) Less(i, j int) bool {
-	return e[i].Range.Start < e[j].Range.Start
+	if e[i].Range.Start != e[j].Range.Start {
+		return e[i].Range.Start < e[j].Range.Start
+	}
+	return e[i].Range.Stop > e[j].Range.Stop
 }
 
 func (e entitiesT) Swap(i, j int) {
@@ -124,6 +217,34 @@ func (t *ByteEntity) ScreenName() (string, bool) {
 	return t.screenName, t.screenNameIsSet
 }
 
+// ScreenNameKey returns the canonical key of the extracted screen name (when
+// Type=Mention) and a boolean indicating whether the value is set. Mentions
+// of the same account have the same key whatever their case and width; see
+// Extractor.ScreenNameKey. The key of a federated mention ends with "@" and
+// the lowercase ASCII form of its domain, so that it differs from the key of
+// a local account with the same name. The return value will be ("", false)
+// when Type != Mention
+func (t *ByteEntity) ScreenNameKey() (string, bool) {
+	return t.screenNameKey, t.screenNameKeyIsSet
+}
+
+// MentionDomain returns the domain of a federated mention such as
+// "@alice@mastodon.social" (when Type=Mention) and a boolean indicating
+// whether the value is set. The return value will be ("", false) when
+// Type != Mention, or for mentions of local accounts. The screen name does
+// not include the domain, but its key does (See: ScreenNameKey)
+func (t *ByteEntity) MentionDomain() (string, bool) {
+	return t.mentionDomain, t.mentionDomainIsSet
+}
+
+// ListSlug returns the slug of the extracted list, e.g. "list-name" for
+// "@owner/list-name" (when Type=List) and a boolean indicating whether the
+// value is set. The owner of the list is returned by ScreenName. The return
+// value will be ("", false) when Type != List
+func (t *ByteEntity) ListSlug() (string, bool) {
+	return t.listSlug, t.listSlugIsSet
+}
+
 // Hashtag Returns the value of the extracted hashtag (when Type=Hashtag) and
 // a boolean indicating whether the value is set. The return value will be
 // ("", false) when Type != Hashtag
@@ -131,138 +252,124 @@ func (t *ByteEntity) Hashtag() (string, bool) {
 	return t.hashtag, t.hashtagIsSet
 }
 
-// Entities extracts all usernames and hashtags from the given text -
-// returned in the order they appear within the input string
-func Entities(text string) []*ByteEntity {
-	var result entitiesT
-	result = URLs(text)
-	result = append(result, Hashtags(text)...)
-	result = append(result, Mentions(text)...)
+// HashtagKey returns the canonical key of the extracted hashtag (when
+// Type=Hashtag) and a boolean indicating whether the value is set. Variants
+// of a hashtag which only differ in normalization, width or case have the same
+// key; see Extractor.HashtagKey. The return value will be ("", false) when
+// Type != Hashtag
+func (t *ByteEntity) HashtagKey() (string, bool) {
+	return t.hashtagKey, t.hashtagKeyIsSet
+}
 
-	sort.Sort(result)
-	result.removeOverlappingEntities()
-	return result
+// Symbol returns the value of the extracted cashtag symbol (when
+// Type=Cashtag) and a boolean indicating whether the value is set. The return
+// value will be ("", false) when Type != Cashtag
+func (t *ByteEntity) Symbol() (string, bool) {
+	return t.cashtag, t.cashtagIsSet
 }
 
-// URLs extracts urls from the given text. Returns a slice of ByteEntity struct
-// pointers.
-func URLs(text string) []*ByteEntity {
-	// This giant pile of barf is copied from the various twitter-text
-	// implementations. There must be a better way!
-	var result entitiesT
-	var (
-		matchStart     int
-		matchEnd       int
-		precedingStart int
-		precedingEnd   int
-		domainStart    int
-		domainEnd      int
-		pathStart      int
-		pathEnd        int
-	)
-
-	// Start at the beginning of the input string, walking forward one match at
-	// a time. We have to walk the string because the regexp package lacks
-	// support for lookahead assertions
-	offset := 0
-	nextOffset := 0
-	for {
-		offset = nextOffset
-		substr := text[offset:]
-		match := validURL.FindStringSubmatchIndex(substr)
-
-		// If no matches are found in this portion of the string, we're done
-		if match == nil {
-			break
-		}
+// URLParts returns the components of the extracted URL (when Type=URL) and a
+// boolean indicating whether the value is set. The return value will be
+// (URLParts{}, false) when Type != URL
+func (t *ByteEntity) URLParts() (URLParts, bool) {
+	return t.urlParts, t.urlPartsIsSet
+}
 
-		// Next time around, start at the end of the current match, minus 1
-		// because indices are not inclusive
-		nextOffset = match[1] + offset - 1
-
-		matchStart = match[validURLGroupURL*2]
-		matchEnd = match[validURLGroupURL*2+1]
-
-		// If protocol is missing, only extract ascii domains
-		if match[validURLGroupProtocol*2] < 0 {
-			var lastEntity *ByteEntity
-			lastInvalid := false
-			precedingStart = match[validURLGroupBefore*2]
-			precedingEnd = match[validURLGroupBefore*2+1]
-			domainStart = match[validURLGroupDomain*2]
-			domainEnd = match[validURLGroupDomain*2+1]
-			pathStart = match[validURLGroupPath*2]
-			pathEnd = match[validURLGroupPath*2+1]
-
-			// check for invalid preceding character
-			if invalidURLWithoutProtocolMatchBegin.MatchString(
-				substr[precedingStart:precedingEnd],
-			) {
-				continue
-			}
+// HostSpoofing returns the analysis of the extracted URL's host for
+// mixed-script and confusable labels, and for lookalikes of the Extractor's
+// protected domains (when Type=URL), and a boolean indicating whether the
+// value is set. The value is not set when Type != URL, or when the host is not
+// a valid internationalized domain name
+func (t *ByteEntity) HostSpoofing() (HostSpoofing, bool) {
+	return t.hostSpoofing, t.hostSpoofingIsSet
+}
 
-			// Make sure the protocol-less domain is ascii only
-			// e.g., in the case of "한국twitter.com", only extract twitter.com
-			if m := validASCIIDomain.FindStringSubmatchIndex(
-				substr[domainStart:domainEnd],
-			); m != nil {
-				lastEntity = &ByteEntity{
-					Text: substr[matchStart+m[0] : matchStart+m[1]],
-					ByteRange: Range{
-						Start: matchStart + offset + m[0],
-						Stop:  matchStart + offset + m[1]},
-					Type: URL}
-
-				// Set the next offset to the end of this match
-				nextOffset = matchStart + m[1] + offset - 1
-
-				// If the url has a Generic TLD (not CC TLD), it's valid
-				if lastInvalid = invalidShortDomain.MatchString(
-					lastEntity.Text,
-				); !lastInvalid {
-					result = append(result, lastEntity)
-				}
-			}
+// NormalizedURL returns the extracted URL as an absolute URL (when
+// Type=URL) and a boolean indicating whether the value is set. URLs without
+// a protocol are prefixed with "http://", and the scheme and host are
+// lowercased. The return value will be ("", false) when Type != URL
+func (t *ByteEntity) NormalizedURL() (string, bool) {
+	if !t.urlPartsIsSet {
+		return "", false
+	}
+	return t.urlParts.String(), true
+}
+
+// Entities extracts all usernames, hashtags, cashtags and URLs from the given
+// text - returned in the order they appear within the input string
+func Entities(text string) []*ByteEntity {
+	return defaultExtractor.Entities(text)
+}
 
-			if lastEntity == nil {
-				continue
+// Entities extracts all entities of the types enabled in the Extractor's
+// options - returned in the order they appear within the input string
+func (x *Extractor) Entities(text string) []*ByteEntity {
+	s := x.scan(text, scanAll)
+	var result entitiesT
+	result = append(result, s.urls...)
+	result = append(result, s.hashtagsOutsideURLs()...)
+	result = append(result, s.mentions...)
+	result = append(result, s.cashtags...)
+
+	result = x.overlaps.resolve(result)
+
+	// Overlaps are resolved before filtering so that disabling a type does
+	// not change which entities of the other types are found
+	if x.entityTypes != nil {
+		n := 0
+		for _, e := range result {
+			if x.returnsType(e.Type) {
+				result[n] = e
+				n++
 			}
+		}
+		result = result[:n]
+	}
+	x.annotate(result)
+	return result
+}
 
-			// If the match contains a path immediately following the domain,
-			// append it to the match
-			if pathStart > 0 && pathStart == lastEntity.ByteRange.Stop-offset {
-				// If the last result was invalid b/c it did not contain a GTLD,
-				// append it
-				if lastInvalid {
-					result = append(result, lastEntity)
-				}
-
-				// Update the text and offsets
-				lastEntity.Text += substr[pathStart:pathEnd]
-				lastEntity.ByteRange.Stop = pathEnd + offset
-				nextOffset = lastEntity.ByteRange.Stop - 1
-			} else if validSpecialShortDomain.MatchString(lastEntity.Text) {
-				result = append(result, lastEntity)
+// annotate sets the values derived from the entities which are returned:
+// the keys of mentions and hashtags, and the spoofing analysis of URL hosts
+func (x *Extractor) annotate(entities []*ByteEntity) {
+	for _, e := range entities {
+		switch e.Type {
+		case Mention, List:
+			e.screenNameKey, e.screenNameKeyIsSet = x.ScreenNameKey(e.screenName), true
+			if e.mentionDomainIsSet {
+				e.screenNameKey += "@" + mentionDomainKey(e.mentionDomain)
 			}
-		} else {
-			// Else, the url contains a protocol
-			url := substr[matchStart:matchEnd]
-			// If it's a t.co url, restrict to certain path characters
-			if tcoLoc := validTcoURL.FindStringIndex(url); tcoLoc != nil {
-				url = url[tcoLoc[0]:tcoLoc[1]]
-				matchEnd = matchStart + len(url)
+		case Hashtag:
+			e.hashtagKey, e.hashtagKeyIsSet = x.HashtagKey(e.hashtag), true
+		case URL:
+			if host := e.urlParts.UnicodeHost; host != "" {
+				e.hostSpoofing, e.hostSpoofingIsSet = x.protectedDomains.check(host), true
 			}
-			result = append(result,
-				&ByteEntity{Text: url,
-					ByteRange: Range{
-						Start: matchStart + offset,
-						Stop:  matchEnd + offset},
-					Type: URL})
 		}
 	}
+}
+
+// mentionDomainKey returns the canonical form of the domain of a federated
+// mention: its lowercase ASCII form, or the lowercase domain if it cannot be
+// converted
+func mentionDomainKey(domain string) string {
+	if ascii, err := HostToASCII(domain); err == nil {
+		domain = ascii
+	}
+	return strings.ToLower(domain)
+}
+
+// URLs extracts urls from the given text. Returns a slice of ByteEntity struct
+// pointers.
+func URLs(text string) []*ByteEntity {
+	return defaultExtractor.URLs(text)
+}
 
-	// Add character/rune offsets in addition to byte offsets
-	result.fixIndices(text)
+// URLs extracts urls from the given text using the Extractor's TLDs and
+// protocol settings
+func (x *Extractor) URLs(text string) []*ByteEntity {
+	result := x.scan(text, scanURLs).urls
+	x.annotate(result)
 	return result
 }
 
@@ -271,7 +378,13 @@ func URLs(text string) []*ByteEntity {
 // The ScreenName field in the returned structs will contain the value of the
 // referenced username without the leading @ sign
 func MentionedScreenNames(text string) []*ByteEntity {
-	mentions := Mentions(text)
+	return defaultExtractor.MentionedScreenNames(text)
+}
+
+// MentionedScreenNames extracts @username mentions from the supplied text
+// using the Extractor's username rules
+func (x *Extractor) MentionedScreenNames(text string) []*ByteEntity {
+	mentions := x.Mentions(text)
 	var result []*ByteEntity
 	for _, e := range mentions {
 		result = append(result, e)
@@ -284,43 +397,40 @@ func MentionedScreenNames(text string) []*ByteEntity {
 // The ScreenName field in the returned structs will contain the value of the
 // referenced username without the leading @ sign.
 func Mentions(text string) []*ByteEntity {
-	// Optimization
-	if !strings.ContainsAny(text, "@＠") {
-		return nil
-	}
+	return defaultExtractor.Mentions(text)
+}
 
+// Mentions extracts @username mentions from the supplied text using the
+// Extractor's username length bounds and separators. Lists such as
+// "@owner/list-name" are not mentions; see MentionsOrLists
+func (x *Extractor) Mentions(text string) []*ByteEntity {
 	var result entitiesT
-	matches := validMention.FindAllStringSubmatchIndex(text, -1)
-	for _, m := range matches {
-		matchEnd := text[m[1]:]
-		if invalidMentionMatchEnd.MatchString(matchEnd) {
-			continue
-		}
-
-		atSignStart := m[validMentionGroupAt*2]
-		screennameStart := m[validMentionGroupUsername*2]
-		screennameEnd := m[validMentionGroupUsername*2+1]
-
-		if screennameEnd-screennameStart < 3 || screennameEnd-screennameStart > 20 {
-			continue
+	for _, e := range x.scan(text, scanMentions).mentions {
+		if e.Type == Mention {
+			result = append(result, e)
 		}
-
-		start := atSignStart
-		stop := screennameEnd
-
-		result = append(result, &ByteEntity{
-			Text:            text[start:stop],
-			screenName:      text[screennameStart:screennameEnd],
-			screenNameIsSet: true,
-			ByteRange: Range{
-				Start: start,
-				Stop:  stop,
-			},
-			Type: Mention,
-		})
 	}
+	x.annotate(result)
+	return result
+}
+
+// MentionsOrLists extracts @username mentions and @owner/list-name lists
+// from the supplied text. Returns a slice of ByteEntity struct pointers, in
+// the order they appear within the input string.
+// The ScreenName field in the returned structs will contain the value of the
+// referenced username or list owner without the leading @ sign, and the
+// ListSlug field the name of the list.
+func MentionsOrLists(text string) []*ByteEntity {
+	return defaultExtractor.MentionsOrLists(text)
+}
 
-	result.fixIndices(text)
+// MentionsOrLists extracts @username mentions and @owner/list-name lists
+// from the supplied text using the Extractor's username rules. A list slug
+// starts with an ASCII letter and has at most 25 ASCII letters, digits,
+// underscores and hyphens; the owner follows the rules of usernames
+func (x *Extractor) MentionsOrLists(text string) []*ByteEntity {
+	result := x.scan(text, scanMentions).mentions
+	x.annotate(result)
 	return result
 }
 
@@ -329,62 +439,26 @@ func Mentions(text string) []*ByteEntity {
 // The Hashtag field of the returned entities will contain the value of the
 // extracted hashtag without the leading # character
 func Hashtags(text string) []*ByteEntity {
-	return extractHashtags(text, true)
+	return defaultExtractor.Hashtags(text)
 }
 
-func extractHashtags(text string, checkURLOverlap bool) []*ByteEntity {
-	// Optimization
-	if !strings.ContainsAny(text, "#＃") {
-		return nil
-	}
-	var result entitiesT
-	var hashStart int
-	var hashtagStart int
-	var hashtagEnd int
-	for _, match := range validHashtag.FindAllStringSubmatchIndex(text, -1) {
-		if invalidHashtagMatchEnd.MatchString(text[match[1]:]) {
-			continue
-		}
-		hashStart = match[validHashtagGroupHash*2]
-		hashtagStart = match[validHashtagGroupTag*2]
-		hashtagEnd = match[validHashtagGroupTag*2+1]
-		result = append(result, &ByteEntity{
-			Text:         text[hashStart:hashtagEnd],
-			hashtag:      text[hashtagStart:hashtagEnd],
-			hashtagIsSet: true,
-			ByteRange: Range{
-				Start: hashStart,
-				Stop:  hashtagEnd,
-			},
-			Type: Hashtag,
-		})
-	}
-
-	result.fixIndices(text)
-
-	if checkURLOverlap {
-		urls := URLs(text)
-		result = append(result, urls...)
-		sort.Sort(result)
-		result.removeOverlappingEntities()
-
-		numHashtags := 0
-		for _, e := range result {
-			if e.Type == Hashtag {
-				result[numHashtags] = e
-				numHashtags++
-			}
-		}
-		result = result[:numHashtags]
+// Hashtags extracts #hashtag occurrences from the supplied text, dropping any
+// that are part of a URL recognized by the Extractor
+func (x *Extractor) Hashtags(text string) []*ByteEntity {
+	result := x.scan(text, scanURLs|scanHashtags).hashtagsOutsideURLs()
+	x.annotate(result)
+	return result
+}
 
-		var tmpResult []*ByteEntity
-		for _, e := range result {
-			if e.Type == Hashtag {
-				tmpResult = append(tmpResult, e)
-			}
-		}
-		result = tmpResult
-	}
+// Cashtags extracts $SYMBOL occurrences from the supplied text. Returns a
+// slice of ByteEntity struct pointers.
+// The Symbol field of the returned entities will contain the value of the
+// extracted symbol without the leading $ character
+func Cashtags(text string) []*ByteEntity {
+	return defaultExtractor.Cashtags(text)
+}
 
-	return result
+// Cashtags extracts $SYMBOL occurrences from the supplied text
+func (x *Extractor) Cashtags(text string) []*ByteEntity {
+	return x.scan(text, scanCashtags).cashtags
 }
@@ -174,3 +174,101 @@ func TestHashtagsWithIndices(t *testing.T) {
 		}
 	}
 }
+
+func TestHashtagKey(t *testing.T) {
+	contents, err := ioutil.ReadFile(extractYmlPath)
+	if err != nil {
+		t.Errorf("Error reading extract.yml: %v", err)
+		t.FailNow()
+	}
+
+	var conformance = &Conformance{}
+	err = goyaml.Unmarshal(contents, &conformance)
+	if err != nil {
+		t.Errorf("Error parsing extract.yml: %v", err)
+		t.FailNow()
+	}
+
+	turkishOptions := DefaultExtractorOptions()
+	turkishOptions.TurkicCaseFolding = true
+	for section, x := range map[string]*Extractor{
+		"hashtag_keys":        defaultExtractor,
+		"hashtag_keys_turkic": mustNewExtractor(turkishOptions),
+	} {
+		keyTests, ok := conformance.Tests[section]
+		if !ok {
+			t.Errorf("Conformance file did not contain '%s' key", section)
+			t.FailNow()
+		}
+
+		for _, test := range keyTests {
+			if actual := x.HashtagKey(test.Text); actual != test.Expected {
+				t.Errorf(
+					"HashtagKey returned incorrect value for test: [%s]. Expected:[%v] Got:[%s]\n",
+					test.Description,
+					test.Expected,
+					actual,
+				)
+			}
+
+			// Extracted hashtags carry the same key
+			for _, e := range x.Hashtags(test.Text) {
+				if key, _ := e.HashtagKey(); key != test.Expected {
+					t.Errorf(
+						"Hashtags returned incorrect key for test: [%s]. Expected:[%v] Got:[%s]\n",
+						test.Description,
+						test.Expected,
+						key,
+					)
+				}
+			}
+		}
+	}
+}
+
+func TestGroupHashtags(t *testing.T) {
+	contents, err := ioutil.ReadFile(extractYmlPath)
+	if err != nil {
+		t.Errorf("Error reading extract.yml: %v", err)
+		t.FailNow()
+	}
+
+	var conformance = &Conformance{}
+	err = goyaml.Unmarshal(contents, &conformance)
+	if err != nil {
+		t.Errorf("Error parsing extract.yml: %v", err)
+		t.FailNow()
+	}
+
+	groupTests, ok := conformance.Tests["hashtag_groups"]
+	if !ok {
+		t.Errorf("Conformance file did not contain 'hashtag_groups' key")
+		t.FailNow()
+	}
+
+	for _, test := range groupTests {
+		result := GroupHashtags(Entities(test.Text))
+
+		var expected []HashtagGroup
+		for _, e := range test.Expected.([]interface{}) {
+			expectedMap := e.(map[interface{}]interface{})
+			group := HashtagGroup{
+				Key:   expectedMap["key"].(string),
+				Count: expectedMap["count"].(int),
+			}
+			for _, variant := range expectedMap["variants"].([]interface{}) {
+				group.Variants = append(group.Variants, variant.(string))
+			}
+			expected = append(expected, group)
+		}
+
+		if fmt.Sprintf("%q", result) != fmt.Sprintf("%q", expected) {
+			t.Errorf(
+				"GroupHashtags returned incorrect value for test: [%s]. Expected:%q Got:%q\n",
+				test.Description,
+				expected,
+				result,
+			)
+		}
+	}
+}
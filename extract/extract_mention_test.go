@@ -175,3 +175,358 @@ func TestMentionsWithIndices(t *testing.T) {
 		}
 	}
 }
+
+func TestMentionsWithKeys(t *testing.T) {
+	contents, err := ioutil.ReadFile(extractYmlPath)
+	if err != nil {
+		t.Errorf("Error reading extract.yml: %v", err)
+		t.FailNow()
+	}
+
+	var conformance = &Conformance{}
+	err = goyaml.Unmarshal(contents, &conformance)
+	if err != nil {
+		t.Errorf("Error parsing extract.yml: %v", err)
+		t.FailNow()
+	}
+
+	mentionTests, ok := conformance.Tests["mentions_with_keys"]
+	if !ok {
+		t.Errorf("Conformance file did not contain 'mentions_with_keys' key")
+		t.FailNow()
+	}
+
+	for _, test := range mentionTests {
+		result := MentionedScreenNames(test.Text)
+
+		expected, ok := test.Expected.([]interface{})
+		if !ok {
+			t.Errorf(
+				"Expected value in conformance file was not a list. Test name: %s.\n",
+				test.Description,
+			)
+			t.FailNow()
+		}
+
+		if len(result) != len(expected) {
+			t.Errorf(
+				"Wrong number of entities returned for text [%s]. Expected:%v Got:%v.\n",
+				test.Text,
+				expected,
+				result,
+			)
+			continue
+		}
+
+		for n, e := range expected {
+			actual := result[n]
+			expectedMap, _ := e.(map[interface{}]interface{})
+			screenName, _ := actual.ScreenName()
+			key, ok := actual.ScreenNameKey()
+			if !ok {
+				t.Errorf("MentionedScreenNames returned entity without key for test [%s]", test.Description)
+			}
+			if screenName != expectedMap["screen_name"] || key != expectedMap["key"] {
+				t.Errorf(
+					"MentionedScreenNames returned incorrect value for test: [%s]. Expected:[%v %v] Got:[%s %s]\n",
+					test.Description,
+					expectedMap["screen_name"],
+					expectedMap["key"],
+					screenName,
+					key,
+				)
+			}
+		}
+	}
+}
+
+func TestScreenNameKey(t *testing.T) {
+	contents, err := ioutil.ReadFile(extractYmlPath)
+	if err != nil {
+		t.Errorf("Error reading extract.yml: %v", err)
+		t.FailNow()
+	}
+
+	var conformance = &Conformance{}
+	err = goyaml.Unmarshal(contents, &conformance)
+	if err != nil {
+		t.Errorf("Error parsing extract.yml: %v", err)
+		t.FailNow()
+	}
+
+	keyTests, ok := conformance.Tests["screen_name_keys"]
+	if !ok {
+		t.Errorf("Conformance file did not contain 'screen_name_keys' key")
+		t.FailNow()
+	}
+
+	for _, test := range keyTests {
+		if actual := ScreenNameKey(test.Text); actual != test.Expected {
+			t.Errorf(
+				"ScreenNameKey returned incorrect value for test: [%s]. Expected:[%v] Got:[%s]\n",
+				test.Description,
+				test.Expected,
+				actual,
+			)
+		}
+	}
+}
+
+func TestFederatedMentions(t *testing.T) {
+	contents, err := ioutil.ReadFile(extractYmlPath)
+	if err != nil {
+		t.Errorf("Error reading extract.yml: %v", err)
+		t.FailNow()
+	}
+
+	var conformance = &Conformance{}
+	err = goyaml.Unmarshal(contents, &conformance)
+	if err != nil {
+		t.Errorf("Error parsing extract.yml: %v", err)
+		t.FailNow()
+	}
+
+	mentionTests, ok := conformance.Tests["federated_mentions"]
+	if !ok {
+		t.Errorf("Conformance file did not contain 'federated_mentions' key")
+		t.FailNow()
+	}
+
+	options := DefaultExtractorOptions()
+	options.FederatedMentions = true
+	x, err := NewExtractor(options)
+	if err != nil {
+		t.Fatalf("NewExtractor failed: %v", err)
+	}
+
+	for _, test := range mentionTests {
+		result := x.Mentions(test.Text)
+
+		expected, ok := test.Expected.([]interface{})
+		if !ok {
+			t.Errorf(
+				"Expected value in conformance file was not a list. Test name: %s.\n",
+				test.Description,
+			)
+			t.FailNow()
+		}
+
+		if len(result) != len(expected) {
+			t.Errorf(
+				"Wrong number of entities returned for text [%s]. Expected:%v Got:%v.\n",
+				test.Text,
+				expected,
+				result,
+			)
+			continue
+		}
+
+		for n, e := range expected {
+			actual := result[n]
+			expectedMap, _ := e.(map[interface{}]interface{})
+			screenName, _ := actual.ScreenName()
+			domain, hasDomain := actual.MentionDomain()
+			expectedDomain, expectDomain := expectedMap["domain"]
+			if screenName != expectedMap["screen_name"] || hasDomain != expectDomain ||
+				expectDomain && domain != expectedDomain {
+				t.Errorf(
+					"Mentions returned incorrect value for test: [%s]. Expected:[%v %v] Got:[%s %s]\n",
+					test.Description,
+					expectedMap["screen_name"],
+					expectedDomain,
+					screenName,
+					domain,
+				)
+			}
+
+			key, _ := actual.ScreenNameKey()
+			if expectedKey, ok := expectedMap["key"]; ok && key != expectedKey {
+				t.Errorf(
+					"Mentions returned incorrect key for test: [%s]. Expected:[%v] Got:[%s]\n",
+					test.Description,
+					expectedKey,
+					key,
+				)
+			}
+
+			indices, _ := expectedMap["indices"].([]interface{})
+			if len(indices) != 2 || indices[0] != actual.Range.Start || indices[1] != actual.Range.Stop {
+				t.Errorf(
+					"Mentions did not return correct indices for test: [%s]. Expected:%v Got:%s\n",
+					test.Description,
+					indices,
+					actual.Range,
+				)
+			}
+		}
+
+		for _, e := range Mentions(test.Text) {
+			if _, ok := e.MentionDomain(); ok {
+				t.Errorf("Mentions returned a federated mention by default for test: [%s]", test.Description)
+			}
+		}
+	}
+}
+
+func TestMentionsOrListsWithIndices(t *testing.T) {
+	contents, err := ioutil.ReadFile(extractYmlPath)
+	if err != nil {
+		t.Errorf("Error reading extract.yml: %v", err)
+		t.FailNow()
+	}
+
+	var conformance = &Conformance{}
+	err = goyaml.Unmarshal(contents, &conformance)
+	if err != nil {
+		t.Errorf("Error parsing extract.yml: %v", err)
+		t.FailNow()
+	}
+
+	mentionTests, ok := conformance.Tests["mentions_or_lists_with_indices"]
+	if !ok {
+		t.Errorf("Conformance file did not contain 'mentions_or_lists_with_indices' key")
+		t.FailNow()
+	}
+
+	for _, test := range mentionTests {
+		result := MentionsOrLists(test.Text)
+
+		expected, ok := test.Expected.([]interface{})
+		if !ok {
+			t.Errorf(
+				"Expected value in conformance file was not a list. Test name: %s.\n",
+				test.Description,
+			)
+			t.FailNow()
+		}
+
+		if len(result) != len(expected) {
+			t.Errorf(
+				"Wrong number of entities returned for text [%s]. Expected:%v Got:%v.\n",
+				test.Text,
+				expected,
+				result,
+			)
+			continue
+		}
+
+		for n, e := range expected {
+			actual := result[n]
+			expectedMap, _ := e.(map[interface{}]interface{})
+			screenName, _ := actual.ScreenName()
+			slug, isList := actual.ListSlug()
+			expectedSlug, expectList := expectedMap["list_slug"]
+			if screenName != expectedMap["screen_name"] || isList != expectList ||
+				expectList && slug != expectedSlug {
+				t.Errorf(
+					"MentionsOrLists returned incorrect value for test: [%s]. Expected:[%v %v] Got:[%s %s]\n",
+					test.Description,
+					expectedMap["screen_name"],
+					expectedSlug,
+					screenName,
+					slug,
+				)
+			}
+			expectedType := Mention
+			if expectList {
+				expectedType = List
+			}
+			if actual.Type != expectedType {
+				t.Errorf(
+					"MentionsOrLists returned entity with wrong type for test: [%s]. Expected:%v Got:%v",
+					test.Description,
+					expectedType,
+					actual.Type,
+				)
+			}
+
+			indices, _ := expectedMap["indices"].([]interface{})
+			if len(indices) != 2 || indices[0] != actual.Range.Start || indices[1] != actual.Range.Stop {
+				t.Errorf(
+					"MentionsOrLists did not return correct indices for test: [%s]. Expected:%v Got:%s\n",
+					test.Description,
+					indices,
+					actual.Range,
+				)
+			}
+		}
+	}
+}
+
+func TestReplyAndRetweetScreenNames(t *testing.T) {
+	contents, err := ioutil.ReadFile(extractYmlPath)
+	if err != nil {
+		t.Errorf("Error reading extract.yml: %v", err)
+		t.FailNow()
+	}
+
+	var conformance = &Conformance{}
+	err = goyaml.Unmarshal(contents, &conformance)
+	if err != nil {
+		t.Errorf("Error parsing extract.yml: %v", err)
+		t.FailNow()
+	}
+
+	for key, extract := range map[string]func(string) (string, bool){
+		"replies":  ReplyScreenName,
+		"retweets": RetweetScreenName,
+	} {
+		tests, ok := conformance.Tests[key]
+		if !ok {
+			t.Errorf("Conformance file did not contain '%s' key", key)
+			continue
+		}
+
+		for _, test := range tests {
+			actual, ok := extract(test.Text)
+			if test.Expected == nil && ok || test.Expected != nil && (!ok || actual != test.Expected) {
+				t.Errorf(
+					"Extracting %s returned incorrect value for test: [%s]. Expected:[%v] Got:[%s %v]\n",
+					key,
+					test.Description,
+					test.Expected,
+					actual,
+					ok,
+				)
+			}
+		}
+	}
+}
+
+func TestReplyMentions(t *testing.T) {
+	contents, err := ioutil.ReadFile(extractYmlPath)
+	if err != nil {
+		t.Errorf("Error reading extract.yml: %v", err)
+		t.FailNow()
+	}
+
+	var conformance = &Conformance{}
+	err = goyaml.Unmarshal(contents, &conformance)
+	if err != nil {
+		t.Errorf("Error parsing extract.yml: %v", err)
+		t.FailNow()
+	}
+
+	mentionTests, ok := conformance.Tests["reply_mentions"]
+	if !ok {
+		t.Errorf("Conformance file did not contain 'reply_mentions' key")
+		t.FailNow()
+	}
+
+	for _, test := range mentionTests {
+		var actual []string
+		for _, e := range ReplyMentions(test.Text) {
+			screenName, _ := e.ScreenName()
+			actual = append(actual, screenName)
+		}
+
+		if fmt.Sprint(actual) != fmt.Sprint(test.Expected) {
+			t.Errorf(
+				"ReplyMentions returned incorrect value for test: [%s]. Expected:%v Got:%v\n",
+				test.Description,
+				test.Expected,
+				actual,
+			)
+		}
+	}
+}
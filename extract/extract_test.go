@@ -49,3 +49,15 @@ func ExampleMentionedScreenNames() {
 	// Match[1]:@user2 Screenname:user2 Range:(15, 21)
 	// Match[2]:@user3 Screenname:user3 Range:(26, 32)
 }
+
+func ExampleCashtags() {
+	text := "$TSLA is up, $BRK.A is flat"
+	for _, e := range Cashtags(text) {
+		symbol, _ := e.Symbol()
+		fmt.Printf("Match:%s Symbol:%s Range:%s\n", e.Text, symbol, e.Range)
+	}
+
+	// Output:
+	// Match:$TSLA Symbol:TSLA Range:(0, 5)
+	// Match:$BRK.A Symbol:BRK.A Range:(13, 19)
+}
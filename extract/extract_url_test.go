@@ -253,4 +253,204 @@ func TestTlds(t *testing.T) {
 			}
 		}
 	}
-}
\ No newline at end of file
+}
+func TestURLsWithParts(t *testing.T) {
+	contents, err := ioutil.ReadFile(extractYmlPath)
+	if err != nil {
+		t.Errorf("Error reading extract.yml: %v", err)
+		t.FailNow()
+	}
+
+	var conformance = &Conformance{}
+	err = goyaml.Unmarshal(contents, &conformance)
+	if err != nil {
+		t.Errorf("Error parsing extract.yml: %v", err)
+		t.FailNow()
+	}
+
+	urlTests, ok := conformance.Tests["urls_with_parts"]
+	if !ok {
+		t.Errorf("Conformance file did not contain 'urls_with_parts' key")
+		t.FailNow()
+	}
+
+	for _, test := range urlTests {
+		result := URLs(test.Text)
+
+		expected, ok := test.Expected.([]interface{})
+		if !ok {
+			t.Errorf(
+				"Expected value in conformance file was not a list. Test name: %s.\n",
+				test.Description,
+			)
+			t.FailNow()
+		}
+
+		if len(result) != len(expected) {
+			t.Errorf(
+				"Wrong number of entities returned for text [%s]. Expected:%v Got:%v.\n",
+				test.Text,
+				expected,
+				result,
+			)
+			continue
+		}
+
+		for n, e := range expected {
+			actual := result[n]
+			expectedMap, ok := e.(map[interface{}]interface{})
+			if !ok {
+				t.Errorf(
+					"Expected value was not a map. Test name: %s\n",
+					test.Description,
+				)
+				continue
+			}
+
+			parts, ok := actual.URLParts()
+			if !ok {
+				t.Errorf("URLs returned entity without parts for test [%s]", test.Description)
+				continue
+			}
+			normalized, _ := actual.NormalizedURL()
+
+			for key, value := range map[string]string{
+				"url":        actual.Text,
+				"scheme":     parts.Scheme,
+				"host":       parts.Host,
+				"port":       parts.Port,
+				"path":       parts.Path,
+				"query":      parts.Query,
+				"fragment":   parts.Fragment,
+				"normalized": normalized,
+			} {
+				if expectedMap[key] != value {
+					t.Errorf(
+						"URLs returned incorrect %s for test: [%s]. Expected:[%v] Got:[%s]\n",
+						key,
+						test.Description,
+						expectedMap[key],
+						value,
+					)
+				}
+			}
+
+			// The converted hosts are only checked by tests which list them
+			for key, value := range map[string]string{
+				"ascii_host":   parts.ASCIIHost,
+				"unicode_host": parts.UnicodeHost,
+			} {
+				if want, ok := expectedMap[key]; ok && want != value {
+					t.Errorf(
+						"URLs returned incorrect %s for test: [%s]. Expected:[%v] Got:[%s]\n",
+						key,
+						test.Description,
+						want,
+						value,
+					)
+				}
+			}
+		}
+	}
+}
+
+func TestURLHostSpoofing(t *testing.T) {
+	contents, err := ioutil.ReadFile(extractYmlPath)
+	if err != nil {
+		t.Errorf("Error reading extract.yml: %v", err)
+		t.FailNow()
+	}
+
+	var conformance = &Conformance{}
+	err = goyaml.Unmarshal(contents, &conformance)
+	if err != nil {
+		t.Errorf("Error parsing extract.yml: %v", err)
+		t.FailNow()
+	}
+
+	urlTests, ok := conformance.Tests["url_host_spoofing"]
+	if !ok {
+		t.Errorf("Conformance file did not contain 'url_host_spoofing' key")
+		t.FailNow()
+	}
+
+	options := DefaultExtractorOptions()
+	options.ProtectedDomains = []string{"paypal.com", "apple.com", "google.com"}
+	x, err := NewExtractor(options)
+	if err != nil {
+		t.Fatalf("NewExtractor failed: %v", err)
+	}
+
+	for _, test := range urlTests {
+		result := x.URLs(test.Text)
+
+		expected, ok := test.Expected.([]interface{})
+		if !ok {
+			t.Errorf(
+				"Expected value in conformance file was not a list. Test name: %s.\n",
+				test.Description,
+			)
+			t.FailNow()
+		}
+
+		if len(result) != len(expected) {
+			t.Errorf(
+				"Wrong number of entities returned for text [%s]. Expected:%v Got:%v.\n",
+				test.Text,
+				expected,
+				result,
+			)
+			continue
+		}
+
+		for n, e := range expected {
+			actual := result[n]
+			expectedMap, _ := e.(map[interface{}]interface{})
+			if actual.Text != expectedMap["url"] {
+				t.Errorf(
+					"URLs returned incorrect url for test: [%s]. Expected:[%v] Got:[%s]\n",
+					test.Description,
+					expectedMap["url"],
+					actual.Text,
+				)
+			}
+
+			spoofing, ok := actual.HostSpoofing()
+			if analysed, present := expectedMap["analysed"].(bool); present {
+				if ok != analysed {
+					t.Errorf(
+						"HostSpoofing returned incorrect set value for test: [%s]. Expected:[%v] Got:[%v]\n",
+						test.Description,
+						analysed,
+						ok,
+					)
+				}
+				continue
+			}
+			if !ok {
+				t.Errorf("URLs returned entity without host spoofing for test [%s]", test.Description)
+				continue
+			}
+
+			mixedScript, _ := expectedMap["mixed_script"].(bool)
+			wholeScript, _ := expectedMap["whole_script_confusable"].(bool)
+			lookalike, _ := expectedMap["lookalike"].(string)
+			for key, values := range map[string][2]interface{}{
+				"level":                   {expectedMap["level"], spoofing.Level.String()},
+				"mixed_script":            {mixedScript, spoofing.MixedScript},
+				"whole_script_confusable": {wholeScript, spoofing.WholeScriptConfusable},
+				"lookalike":               {lookalike, spoofing.Lookalike},
+			} {
+				if values[0] != values[1] {
+					t.Errorf(
+						"HostSpoofing returned incorrect %s for test: [%s]. Expected:[%v] Got:[%v]\n",
+						key,
+						test.Description,
+						values[0],
+						values[1],
+					)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package extract
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// ExtractorOptions configures the rules applied by an Extractor. Callers
+// should start from DefaultExtractorOptions and override individual fields;
+// the zero value is not a usable configuration.
+type ExtractorOptions struct {
+	// Bounds on the length of a username, excluding the leading @
+	MinUsernameLength int
+	MaxUsernameLength int
+
+	// Characters that may appear once between the alphanumeric parts of a
+	// username. An empty string only allows alphanumeric usernames
+	UsernameSeparators string
+
+	// The registry of the TLDs a URL's domain may end in. A nil registry
+	// selects DefaultTLDRegistry. Country TLDs are also used to reject
+	// protocol-less short domains such as "foo.jp"
+	TLDs *TLDRegistry
+
+	// Override the generic or country TLDs of the registry. A nil slice
+	// selects the registry's list
+	GenericTLDs []string
+	CountryTLDs []string
+
+	// Whether URLs without an http:// or https:// prefix are extracted
+	ExtractURLsWithoutProtocol bool
+
+	// Whether mentions of accounts on other servers, such as
+	// "@alice@mastodon.social", are extracted. The domain must end in one of
+	// the TLDs, as for URLs, and is returned by ByteEntity.MentionDomain.
+	// Otherwise such mentions are dropped, as they look like email addresses
+	FederatedMentions bool
+
+	// Whether hashtag keys fold the dotted and dotless i following the
+	// Turkish and Azerbaijani rules, where "I" is the uppercase of "ı" and
+	// "İ" the uppercase of "i"
+	TurkicCaseFolding bool
+
+	// Domains whose lookalikes are reported by ByteEntity.HostSpoofing, in
+	// Unicode or punycode form. Subdomains of a protected domain are not
+	// reported
+	ProtectedDomains []string
+
+	// How Entities chooses between overlapping entities. The zero value
+	// keeps the entity which starts first
+	OverlapPolicy OverlapPolicy
+
+	// The entity types in decreasing priority for the KeepPriority policy.
+	// Types which are not listed come last. A nil slice selects
+	// DefaultEntityPriority
+	EntityPriority []EntityType
+
+	// The entity types returned by Entities. A nil slice returns all types.
+	// This does not change the rules themselves: e.g. hashtags within URLs
+	// are still dropped when URLs are not returned
+	EntityTypes []EntityType
+}
+
+// DefaultExtractorOptions returns the options used by the package-level
+// extraction functions
+func DefaultExtractorOptions() ExtractorOptions {
+	return ExtractorOptions{
+		MinUsernameLength:          3,
+		MaxUsernameLength:          20,
+		UsernameSeparators:         defaultUsernameSeparators,
+		ExtractURLsWithoutProtocol: true,
+	}
+}
+
+// Extractor extracts entities from text according to a fixed set of
+// options. An Extractor is immutable once created and is safe for
+// concurrent use by multiple goroutines.
+type Extractor struct {
+	minUsernameLength          int
+	maxUsernameLength          int
+	usernameSeparators         string
+	extractURLsWithoutProtocol bool
+	federatedMentions          bool
+	turkicCaseFolding          bool
+	entityTypes                map[EntityType]bool
+	protectedDomains           protectedDomains
+	overlaps                   overlapResolver
+
+	// The generic and country TLDs, and the country TLDs alone
+	tlds        *tldSet
+	countryTLDs map[string]bool
+}
+
+// The Extractor backing the package-level functions
+var defaultExtractor = mustNewExtractor(DefaultExtractorOptions())
+
+// NewExtractor returns an Extractor using the given options. Returns an error
+// if the options are inconsistent
+func NewExtractor(options ExtractorOptions) (*Extractor, error) {
+	if options.MinUsernameLength < 1 ||
+		options.MaxUsernameLength < options.MinUsernameLength {
+		return nil, fmt.Errorf(
+			"extract: invalid username length bounds [%d, %d]",
+			options.MinUsernameLength,
+			options.MaxUsernameLength,
+		)
+	}
+
+	for _, r := range options.UsernameSeparators {
+		if r > unicode.MaxASCII || !(unicode.IsPunct(r) || unicode.IsSymbol(r)) ||
+			strings.ContainsRune(atSignChars, r) {
+			return nil, fmt.Errorf("extract: invalid username separator %q", r)
+		}
+	}
+
+	registry := options.TLDs
+	if registry == nil {
+		registry = defaultTLDRegistry
+	}
+	gTLDs, err := tldOption(options.GenericTLDs, registry.generic)
+	if err != nil {
+		return nil, err
+	}
+	ccTLDs, err := tldOption(options.CountryTLDs, registry.country)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.OverlapPolicy < KeepFirst || options.OverlapPolicy > KeepPriority {
+		return nil, fmt.Errorf("extract: invalid overlap policy %d", options.OverlapPolicy)
+	}
+
+	protected, err := newProtectedDomains(options.ProtectedDomains)
+	if err != nil {
+		return nil, err
+	}
+
+	x := &Extractor{
+		minUsernameLength:          options.MinUsernameLength,
+		maxUsernameLength:          options.MaxUsernameLength,
+		usernameSeparators:         options.UsernameSeparators,
+		extractURLsWithoutProtocol: options.ExtractURLsWithoutProtocol,
+		federatedMentions:          options.FederatedMentions,
+		turkicCaseFolding:          options.TurkicCaseFolding,
+		protectedDomains:           protected,
+		overlaps:                   newOverlapResolver(options.OverlapPolicy, options.EntityPriority),
+		tlds:                       newTLDSet(gTLDs, ccTLDs),
+		countryTLDs:                make(map[string]bool, len(ccTLDs)),
+	}
+	for _, tld := range ccTLDs {
+		x.countryTLDs[tld] = true
+	}
+
+	if options.EntityTypes != nil {
+		x.entityTypes = make(map[EntityType]bool, len(options.EntityTypes))
+		for _, t := range options.EntityTypes {
+			x.entityTypes[t] = true
+		}
+	}
+	return x, nil
+}
+
+func mustNewExtractor(options ExtractorOptions) *Extractor {
+	x, err := NewExtractor(options)
+	if err != nil {
+		panic(err)
+	}
+	return x
+}
+
+// tldOption returns the TLDs of a TLD list option in rank order, falling
+// back to the registry's list when the option is nil
+func tldOption(tlds []string, registry []string) ([]string, error) {
+	if tlds == nil {
+		return sortTLDs(registry), nil
+	}
+	for _, tld := range tlds {
+		if err := checkTLD(tld); err != nil {
+			return nil, err
+		}
+	}
+	return sortTLDs(tlds), nil
+}
+
+// returnsType reports whether Entities should include entities of the
+// given type
+func (x *Extractor) returnsType(t EntityType) bool {
+	return x.entityTypes == nil || x.entityTypes[t]
+}
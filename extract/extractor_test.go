@@ -0,0 +1,172 @@
+package extract
+
+import (
+	"fmt"
+	"testing"
+)
+
+func entityTexts(entities []*ByteEntity) []string {
+	var result []string
+	for _, e := range entities {
+		result = append(result, e.Text)
+	}
+	return result
+}
+
+func TestExtractorOptions(t *testing.T) {
+	tests := []struct {
+		description string
+		modify      func(o *ExtractorOptions)
+		text        string
+		expected    []string
+	}{
+		{
+			"Default options",
+			func(o *ExtractorOptions) {},
+			"@ab @abc @user_name example.com #tag $TSLA",
+			[]string{"@abc", "@user_name", "example.com", "#tag", "$TSLA"},
+		},
+		{
+			"Custom username length bounds",
+			func(o *ExtractorOptions) { o.MinUsernameLength, o.MaxUsernameLength = 1, 5 },
+			"@a @abcdef @abc",
+			[]string{"@a", "@abc"},
+		},
+		{
+			"No username separators",
+			func(o *ExtractorOptions) { o.UsernameSeparators = "" },
+			"@user.name @username",
+			[]string{"@user", "@username"},
+		},
+		{
+			"Custom username separators",
+			func(o *ExtractorOptions) { o.UsernameSeparators = "-" },
+			"@user-name @user_name",
+			[]string{"@user-name", "@user"},
+		},
+		{
+			"Custom TLDs",
+			func(o *ExtractorOptions) {
+				o.GenericTLDs = []string{"example", "ex"}
+				o.CountryTLDs = []string{}
+			},
+			"http://foo.example http://foo.com foo.ex",
+			[]string{"http://foo.example", "foo.ex"},
+		},
+		{
+			"TLD registry",
+			func(o *ExtractorOptions) {
+				o.TLDs, _ = NewTLDRegistry([]string{"app"}, []string{"uk"})
+			},
+			"foo.app foo.com foo.uk foo.co.uk/path",
+			[]string{"foo.app", "foo.co.uk/path"},
+		},
+		{
+			"TLD registry with overridden country TLDs",
+			func(o *ExtractorOptions) {
+				o.TLDs, _ = NewTLDRegistry([]string{"app"}, []string{"uk"})
+				o.CountryTLDs = []string{"jp"}
+			},
+			"http://foo.app http://foo.uk http://foo.jp",
+			[]string{"http://foo.app", "http://foo.jp"},
+		},
+		{
+			"URLs without protocol disabled",
+			func(o *ExtractorOptions) { o.ExtractURLsWithoutProtocol = false },
+			"example.com http://example.org",
+			[]string{"http://example.org"},
+		},
+		{
+			"Lists",
+			func(o *ExtractorOptions) {},
+			"@owner/list-name #tag @user",
+			[]string{"@owner/list-name", "#tag", "@user"},
+		},
+		{
+			"Only mentions enabled",
+			func(o *ExtractorOptions) { o.EntityTypes = []EntityType{Mention} },
+			"@owner/list-name @user",
+			[]string{"@user"},
+		},
+		{
+			"Keep the first of overlapping entities",
+			func(o *ExtractorOptions) {},
+			"http://example.com/@user",
+			[]string{"http://example.com/@user"},
+		},
+		{
+			"Keep overlapping entities by priority",
+			func(o *ExtractorOptions) {
+				o.OverlapPolicy = KeepPriority
+				o.EntityPriority = []EntityType{Mention, URL}
+			},
+			"http://example.com/@user",
+			[]string{"@user"},
+		},
+		{
+			"Only hashtags enabled",
+			func(o *ExtractorOptions) { o.EntityTypes = []EntityType{Hashtag} },
+			"@user #tag http://example.com/#frag",
+			[]string{"#tag"},
+		},
+	}
+
+	for _, test := range tests {
+		options := DefaultExtractorOptions()
+		test.modify(&options)
+		x, err := NewExtractor(options)
+		if err != nil {
+			t.Errorf("NewExtractor failed for test [%s]: %v", test.description, err)
+			continue
+		}
+
+		actual := entityTexts(x.Entities(test.text))
+		if fmt.Sprint(actual) != fmt.Sprint(test.expected) {
+			t.Errorf(
+				"Entities returned incorrect value for test [%s]. Expected:%v Got:%v",
+				test.description,
+				test.expected,
+				actual,
+			)
+		}
+	}
+}
+
+func TestNewExtractorInvalidOptions(t *testing.T) {
+	tests := []struct {
+		description string
+		modify      func(o *ExtractorOptions)
+	}{
+		{"Zero minimum username length", func(o *ExtractorOptions) { o.MinUsernameLength = 0 }},
+		{"Maximum below minimum", func(o *ExtractorOptions) { o.MaxUsernameLength = 2 }},
+		{"Alphanumeric separator", func(o *ExtractorOptions) { o.UsernameSeparators = "_a" }},
+		{"At sign separator", func(o *ExtractorOptions) { o.UsernameSeparators = "@" }},
+		{"Empty TLD", func(o *ExtractorOptions) { o.GenericTLDs = []string{""} }},
+		{"Unknown overlap policy", func(o *ExtractorOptions) { o.OverlapPolicy = KeepPriority + 1 }},
+		{"TLD with a dot", func(o *ExtractorOptions) { o.CountryTLDs = []string{"co.uk"} }},
+	}
+
+	for _, test := range tests {
+		options := DefaultExtractorOptions()
+		test.modify(&options)
+		if _, err := NewExtractor(options); err == nil {
+			t.Errorf("NewExtractor did not fail for test [%s]", test.description)
+		}
+	}
+}
+
+func ExampleExtractor() {
+	options := DefaultExtractorOptions()
+	options.MaxUsernameLength = 10
+	options.EntityTypes = []EntityType{Mention}
+	x, err := NewExtractor(options)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, e := range x.Entities("@shortname @longusername #tag") {
+		fmt.Printf("Entity:%s Type:%v\n", e.Text, e.Type)
+	}
+	// Output:
+	// Entity:@shortname Type:Mention
+}
@@ -0,0 +1,138 @@
+package extract
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Hashtag special characters which only join or separate the parts of a
+// hashtag, so that removing them does not change which word it is. The
+// Hebrew geresh and gershayim, the ditto mark and the Tibetan tsheg marks
+// are kept: they change the meaning of the letters around them
+const hashtagIgnorableChars = "" +
+	"\u200c" + // ZERO WIDTH NON-JOINER (ZWNJ)
+	"\u200d" + // ZERO WIDTH JOINER (ZWJ)
+	"\ua67e" + // CYRILLIC KAVYKA
+	"\u05be" + // HEBREW PUNCTUATION MAQAF
+	"\u30a0" + // KATAKANA-HIRAGANA DOUBLE HYPHEN
+	"\u30fb" + // KATAKANA MIDDLE DOT
+	"\u00b7" // MIDDLE DOT
+
+// The spacing voiced sound marks, which NFKC would turn into a space followed
+// by the combining mark, are replaced by the combining marks so that they
+// compose with the preceding kana
+var kanaSoundMarks = strings.NewReplacer(
+	"\u309b", "\u3099", // KATAKANA-HIRAGANA VOICED SOUND MARK
+	"\u309c", "\u309a", // KATAKANA-HIRAGANA SEMI-VOICED SOUND MARK
+)
+
+// HashtagKey returns the canonical key of a hashtag, using the default case
+// folding. See Extractor.HashtagKey
+func HashtagKey(hashtag string) string {
+	return defaultExtractor.HashtagKey(hashtag)
+}
+
+// HashtagKey returns the canonical key of a hashtag, with or without the
+// leading hash sign. Variants of a hashtag which only differ in their
+// normalization, width or case have the same key, e.g. "#Café", "#café",
+// "#ＣＡＦÉ" and "#CAFÉ". Accents are kept, since they may distinguish words:
+// "#ＣＡＦＥ" and "#CAFE" have the key "cafe" instead. The key is built by:
+//   - applying NFKC, so that fullwidth and decomposed characters match their
+//     usual forms
+//   - applying full case folding, or the Turkic folding of dotted and
+//     dotless i when the Extractor's TurkicCaseFolding option is set
+//   - removing the special characters which only join or separate the parts
+//     of a hashtag, such as the zero width joiner and the middle dot
+//
+// Keys are in NFC, and are only meant to be compared with each other, not
+// displayed
+func (x *Extractor) HashtagKey(hashtag string) string {
+	hashtag = kanaSoundMarks.Replace(hashtag)
+	hashtag = norm.NFKC.String(hashtag)
+	hashtag = strings.TrimPrefix(hashtag, "#")
+	hashtag = norm.NFKC.String(foldCase(hashtag, x.turkicCaseFolding))
+	hashtag = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(hashtagIgnorableChars, r) {
+			return -1
+		}
+		return r
+	}, hashtag)
+	return norm.NFC.String(hashtag)
+}
+
+// foldCase applies the full case folding of the Unicode CaseFolding.txt data
+// to s, or its Turkic variant when turkic is set. Runes are folded by
+// lowercasing their uppercase form, which matches the data except for the
+// characters handled explicitly
+func foldCase(s string, turkic bool) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		switch {
+		case turkic && r == 'I':
+			b.WriteRune('ı')
+		case turkic && r == 'İ':
+			b.WriteRune('i')
+		case r == 'İ':
+			b.WriteString("i\u0307")
+		case r == 'ı':
+			// Only folded by the Turkic variant, where it is already folded
+			b.WriteRune(r)
+		case r == 'ß' || r == 'ẞ':
+			b.WriteString("ss")
+		default:
+			b.WriteRune(unicode.ToLower(unicode.ToUpper(r)))
+		}
+	}
+	return b.String()
+}
+
+// HashtagGroup is a set of hashtag variants sharing the same key
+type HashtagGroup struct {
+	Key string `json:"key"`
+
+	// The distinct hashtags with this key, without the hash sign, in the
+	// order they first appear
+	Variants []string `json:"variants"`
+
+	// The number of hashtag entities with this key
+	Count int `json:"count"`
+}
+
+// GroupHashtags groups hashtag entities by their key, as returned by
+// ByteEntity.HashtagKey. Groups are returned in the order their first
+// hashtag appears. Entities of other types are ignored
+func GroupHashtags(entities []*ByteEntity) []HashtagGroup {
+	var groups []HashtagGroup
+	index := map[string]int{}
+	for _, e := range entities {
+		key, ok := e.HashtagKey()
+		if !ok {
+			continue
+		}
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, HashtagGroup{Key: key})
+		}
+
+		g := &groups[i]
+		g.Count++
+		if hashtag, _ := e.Hashtag(); !containsString(g.Variants, hashtag) {
+			g.Variants = append(g.Variants, hashtag)
+		}
+	}
+	return groups
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package extract
+
+import (
+	"fmt"
+
+	"golang.org/x/net/idna"
+)
+
+// The UTS #46 processing applied to URL hosts: characters are mapped as for
+// a lookup, except that underscores are allowed like everywhere else in this
+// package. Profiles are nontransitional (IDNA 2008) by default, so ß, ς and
+// the joiners are kept rather than mapped to their IDNA 2003 equivalents.
+// The idna.Transitional option must not be used: it enables transitional
+// processing whatever its argument
+var idnaProfile = idna.New(
+	idna.MapForLookup(),
+	idna.BidiRule(),
+	idna.StrictDomainName(false),
+)
+
+// HostToASCII converts a host name to its ASCII form following UTS #46
+// (See: http://www.unicode.org/reports/tr46), e.g. "☃.net" becomes
+// "xn--n3h.net". The host is also case folded and normalized, so the result
+// is suitable for comparing hosts. Returns an error if a label is not valid
+// under IDNA 2008
+func HostToASCII(host string) (string, error) {
+	ascii, err := idnaProfile.ToASCII(host)
+	if err != nil {
+		return "", fmt.Errorf("extract: invalid host %q: %v", host, err)
+	}
+	return ascii, nil
+}
+
+// HostToUnicode converts a host name to its Unicode form following UTS #46,
+// e.g. "xn--n3h.net" becomes "☃.net". Returns an error if a label is not
+// valid under IDNA 2008, including punycode labels which do not decode to a
+// valid label
+func HostToUnicode(host string) (string, error) {
+	unicode, err := idnaProfile.ToUnicode(host)
+	if err != nil {
+		return "", fmt.Errorf("extract: invalid host %q: %v", host, err)
+	}
+	return unicode, nil
+}
@@ -0,0 +1,506 @@
+package extract
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+)
+
+// MarshalJSON encodes the type as its name, e.g. "Mention"
+func (t EntityType) MarshalJSON() ([]byte, error) {
+	name := t.String()
+	if name == "Unknown" {
+		return nil, fmt.Errorf("extract: cannot marshal unknown entity type %d", int(t))
+	}
+	return json.Marshal(name)
+}
+
+// UnmarshalJSON decodes a type from its name
+func (t *EntityType) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+	parsed, ok := ParseEntityType(name)
+	if !ok {
+		return fmt.Errorf("extract: unknown entity type %q", name)
+	}
+	*t = parsed
+	return nil
+}
+
+// ParseEntityType returns the EntityType with the given name, as returned by
+// String, and a boolean indicating whether the name is known
+func ParseEntityType(name string) (EntityType, bool) {
+	for t := Mention; t.String() != "Unknown"; t++ {
+		if t.String() == name {
+			return t, true
+		}
+	}
+	return 0, false
+}
+
+// MarshalJSON encodes the level as its name, e.g. "Single Script"
+func (l RestrictionLevel) MarshalJSON() ([]byte, error) {
+	name := l.String()
+	if name == "Unknown" {
+		return nil, fmt.Errorf("extract: cannot marshal unknown restriction level %d", int(l))
+	}
+	return json.Marshal(name)
+}
+
+// UnmarshalJSON decodes a level from its name
+func (l *RestrictionLevel) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+	for level := ASCIIOnly; level.String() != "Unknown"; level++ {
+		if level.String() == name {
+			*l = level
+			return nil
+		}
+	}
+	return fmt.Errorf("extract: unknown restriction level %q", name)
+}
+
+// MarshalJSON encodes the range as a [start, stop] pair
+func (r Range) MarshalJSON() ([]byte, error) {
+	return json.Marshal([2]int{r.Start, r.Stop})
+}
+
+// UnmarshalJSON decodes a range from a [start, stop] pair
+func (r *Range) UnmarshalJSON(data []byte) error {
+	var pair []int
+	if err := json.Unmarshal(data, &pair); err != nil {
+		return err
+	}
+	if len(pair) != 2 {
+		return fmt.Errorf("extract: range must have 2 values, got %d", len(pair))
+	}
+	r.Start, r.Stop = pair[0], pair[1]
+	return nil
+}
+
+// jsonEntity is the JSON representation of a ByteEntity. The type specific
+// values are only present for entities of that type
+type jsonEntity struct {
+	Type          EntityType `json:"type"`
+	Text          string     `json:"text"`
+	Indices       Range      `json:"indices"`
+	ByteIndices   Range      `json:"byte_indices"`
+	UTF16Indices  Range      `json:"utf16_indices"`
+	ScreenName    *string    `json:"screen_name,omitempty"`
+	ScreenNameKey *string    `json:"screen_name_key,omitempty"`
+	MentionDomain *string    `json:"domain,omitempty"`
+	ListSlug      *string    `json:"list_slug,omitempty"`
+	Hashtag       *string    `json:"hashtag,omitempty"`
+	HashtagKey    *string    `json:"hashtag_key,omitempty"`
+	Symbol        *string    `json:"symbol,omitempty"`
+	URL           *URLParts  `json:"url,omitempty"`
+
+	HostSpoofing *HostSpoofing `json:"host_spoofing,omitempty"`
+}
+
+// MarshalJSON encodes the entity, including its type specific values. It has
+// a value receiver so that entities stored by value are encoded the same way
+func (t ByteEntity) MarshalJSON() ([]byte, error) {
+	j := jsonEntity{
+		Type:         t.Type,
+		Text:         t.Text,
+		Indices:      t.Range,
+		ByteIndices:  t.ByteRange,
+		UTF16Indices: t.UTF16Range,
+	}
+	if t.screenNameIsSet {
+		j.ScreenName = &t.screenName
+	}
+	if t.screenNameKeyIsSet {
+		j.ScreenNameKey = &t.screenNameKey
+	}
+	if t.mentionDomainIsSet {
+		j.MentionDomain = &t.mentionDomain
+	}
+	if t.listSlugIsSet {
+		j.ListSlug = &t.listSlug
+	}
+	if t.hashtagIsSet {
+		j.Hashtag = &t.hashtag
+	}
+	if t.hashtagKeyIsSet {
+		j.HashtagKey = &t.hashtagKey
+	}
+	if t.cashtagIsSet {
+		j.Symbol = &t.cashtag
+	}
+	if t.urlPartsIsSet {
+		j.URL = &t.urlParts
+	}
+	if t.hostSpoofingIsSet {
+		j.HostSpoofing = &t.hostSpoofing
+	}
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON decodes an entity encoded by MarshalJSON
+func (t *ByteEntity) UnmarshalJSON(data []byte) error {
+	var j jsonEntity
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+
+	*t = ByteEntity{
+		Text:       j.Text,
+		Range:      j.Indices,
+		ByteRange:  j.ByteIndices,
+		UTF16Range: j.UTF16Indices,
+		Type:       j.Type,
+	}
+	if j.ScreenName != nil {
+		t.screenName, t.screenNameIsSet = *j.ScreenName, true
+	}
+	if j.ScreenNameKey != nil {
+		t.screenNameKey, t.screenNameKeyIsSet = *j.ScreenNameKey, true
+	}
+	if j.MentionDomain != nil {
+		t.mentionDomain, t.mentionDomainIsSet = *j.MentionDomain, true
+	}
+	if j.ListSlug != nil {
+		t.listSlug, t.listSlugIsSet = *j.ListSlug, true
+	}
+	if j.Hashtag != nil {
+		t.hashtag, t.hashtagIsSet = *j.Hashtag, true
+	}
+	if j.HashtagKey != nil {
+		t.hashtagKey, t.hashtagKeyIsSet = *j.HashtagKey, true
+	}
+	if j.Symbol != nil {
+		t.cashtag, t.cashtagIsSet = *j.Symbol, true
+	}
+	if j.URL != nil {
+		t.urlParts, t.urlPartsIsSet = *j.URL, true
+	}
+	if j.HostSpoofing != nil {
+		t.hostSpoofing, t.hostSpoofingIsSet = *j.HostSpoofing, true
+	}
+	return nil
+}
+
+// The binary encoding starts with a version byte, followed by the number of
+// entities (for MarshalEntities only) and the entities themselves. Each
+// entity is encoded as:
+//
+//	uvarint  type
+//	string   text
+//	uvarint  range, byte range and UTF-16 range (start and stop of each,
+//	         which cannot be negative)
+//	uvarint  bit set of the type specific values that follow
+//	string   each type specific value present, in bit order
+//
+// where a string is a uvarint length followed by that many bytes. The host
+// spoofing analysis is encoded as a uvarint restriction level, a uvarint bit
+// set of its flags and a string for the lookalike domain. New type
+// specific values are added as new bits, so that data written by older
+// versions can always be read.
+const binaryVersion = 1
+
+const (
+	binaryScreenName = 1 << iota
+	binaryHashtag
+	binarySymbol
+	binaryURLParts
+	binaryURLHosts
+	binaryHostSpoofing
+	binaryScreenNameKey
+	binaryHashtagKey
+	binaryMentionDomain
+	binaryListSlug
+
+	binaryKnownValues = binaryScreenName | binaryHashtag | binarySymbol | binaryURLParts |
+		binaryURLHosts | binaryHostSpoofing | binaryScreenNameKey | binaryHashtagKey |
+		binaryMentionDomain | binaryListSlug
+)
+
+// The flags of the binary host spoofing analysis
+const (
+	binaryMixedScript = 1 << iota
+	binaryWholeScriptConfusable
+)
+
+// MarshalBinary encodes the entity in a compact binary form. Returns an
+// error if one of its ranges has a negative offset
+func (t *ByteEntity) MarshalBinary() ([]byte, error) {
+	return t.appendBinary([]byte{binaryVersion})
+}
+
+// UnmarshalBinary decodes an entity encoded by MarshalBinary
+func (t *ByteEntity) UnmarshalBinary(data []byte) error {
+	d, err := newBinaryDecoder(data)
+	if err != nil {
+		return err
+	}
+	if err := d.entity(t); err != nil {
+		return err
+	}
+	return d.finish()
+}
+
+// MarshalEntities encodes a list of entities in the compact binary form used
+// by ByteEntity.MarshalBinary
+func MarshalEntities(entities []*ByteEntity) ([]byte, error) {
+	data := appendUvarint([]byte{binaryVersion}, uint64(len(entities)))
+	for _, e := range entities {
+		var err error
+		if data, err = e.appendBinary(data); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// UnmarshalEntities decodes a list of entities encoded by MarshalEntities
+func UnmarshalEntities(data []byte) ([]*ByteEntity, error) {
+	d, err := newBinaryDecoder(data)
+	if err != nil {
+		return nil, err
+	}
+
+	n := d.uvarint()
+	if d.err == nil && n > uint64(len(d.data)) {
+		// Every entity takes at least one byte
+		d.err = fmt.Errorf("extract: invalid entity count %d", n)
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	result := make([]*ByteEntity, n)
+	for i := range result {
+		result[i] = &ByteEntity{}
+		if err := d.entity(result[i]); err != nil {
+			return nil, err
+		}
+	}
+	return result, d.finish()
+}
+
+func (t *ByteEntity) appendBinary(data []byte) ([]byte, error) {
+	data = appendUvarint(data, uint64(t.Type))
+	data = appendString(data, t.Text)
+	for _, r := range []Range{t.Range, t.ByteRange, t.UTF16Range} {
+		if r.Start < 0 || r.Stop < 0 {
+			return nil, fmt.Errorf("extract: cannot marshal negative range %v", r)
+		}
+		data = appendUvarint(data, uint64(r.Start))
+		data = appendUvarint(data, uint64(r.Stop))
+	}
+
+	var values uint64
+	if t.screenNameIsSet {
+		values |= binaryScreenName
+	}
+	if t.hashtagIsSet {
+		values |= binaryHashtag
+	}
+	if t.cashtagIsSet {
+		values |= binarySymbol
+	}
+	if t.urlPartsIsSet {
+		values |= binaryURLParts
+	}
+	if t.urlPartsIsSet && (t.urlParts.ASCIIHost != "" || t.urlParts.UnicodeHost != "") {
+		values |= binaryURLHosts
+	}
+	if t.hostSpoofingIsSet {
+		values |= binaryHostSpoofing
+	}
+	if t.screenNameKeyIsSet {
+		values |= binaryScreenNameKey
+	}
+	if t.hashtagKeyIsSet {
+		values |= binaryHashtagKey
+	}
+	if t.mentionDomainIsSet {
+		values |= binaryMentionDomain
+	}
+	if t.listSlugIsSet {
+		values |= binaryListSlug
+	}
+	data = appendUvarint(data, values)
+
+	if t.screenNameIsSet {
+		data = appendString(data, t.screenName)
+	}
+	if t.hashtagIsSet {
+		data = appendString(data, t.hashtag)
+	}
+	if t.cashtagIsSet {
+		data = appendString(data, t.cashtag)
+	}
+	if t.urlPartsIsSet {
+		p := t.urlParts
+		for _, s := range []string{p.Scheme, p.Host, p.Port, p.Path, p.Query, p.Fragment} {
+			data = appendString(data, s)
+		}
+	}
+	if values&binaryURLHosts != 0 {
+		data = appendString(data, t.urlParts.ASCIIHost)
+		data = appendString(data, t.urlParts.UnicodeHost)
+	}
+	if t.hostSpoofingIsSet {
+		s := t.hostSpoofing
+		var flags uint64
+		if s.MixedScript {
+			flags |= binaryMixedScript
+		}
+		if s.WholeScriptConfusable {
+			flags |= binaryWholeScriptConfusable
+		}
+		data = appendUvarint(data, uint64(s.Level))
+		data = appendUvarint(data, flags)
+		data = appendString(data, s.Lookalike)
+	}
+	if t.screenNameKeyIsSet {
+		data = appendString(data, t.screenNameKey)
+	}
+	if t.hashtagKeyIsSet {
+		data = appendString(data, t.hashtagKey)
+	}
+	if t.mentionDomainIsSet {
+		data = appendString(data, t.mentionDomain)
+	}
+	if t.listSlugIsSet {
+		data = appendString(data, t.listSlug)
+	}
+	return data, nil
+}
+
+func appendUvarint(data []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], v)
+	return append(data, buf[:n]...)
+}
+
+func appendString(data []byte, s string) []byte {
+	data = appendUvarint(data, uint64(len(s)))
+	return append(data, s...)
+}
+
+// binaryDecoder reads the binary encoding. The first error encountered is
+// kept in err, after which all reads return zero values
+type binaryDecoder struct {
+	data []byte
+	err  error
+}
+
+func newBinaryDecoder(data []byte) (*binaryDecoder, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("extract: empty binary entity data")
+	}
+	if data[0] != binaryVersion {
+		return nil, fmt.Errorf("extract: unsupported binary entity version %d", data[0])
+	}
+	return &binaryDecoder{data: data[1:]}, nil
+}
+
+func (d *binaryDecoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.data)
+	if n <= 0 {
+		d.err = fmt.Errorf("extract: truncated binary entity data")
+		return 0
+	}
+	d.data = d.data[n:]
+	return v
+}
+
+func (d *binaryDecoder) int() int {
+	v := d.uvarint()
+	if v > uint64(^uint(0)>>1) {
+		d.err = fmt.Errorf("extract: invalid offset %d in binary entity data", v)
+		return 0
+	}
+	return int(v)
+}
+
+func (d *binaryDecoder) string() string {
+	n := d.uvarint()
+	if d.err != nil {
+		return ""
+	}
+	if n > uint64(len(d.data)) {
+		d.err = fmt.Errorf("extract: truncated binary entity data")
+		return ""
+	}
+	s := string(d.data[:n])
+	d.data = d.data[n:]
+	return s
+}
+
+func (d *binaryDecoder) entity(t *ByteEntity) error {
+	*t = ByteEntity{}
+	t.Type = EntityType(d.int())
+	t.Text = d.string()
+	for _, r := range []*Range{&t.Range, &t.ByteRange, &t.UTF16Range} {
+		r.Start = d.int()
+		r.Stop = d.int()
+	}
+
+	values := d.uvarint()
+	if d.err == nil && values&^binaryKnownValues != 0 {
+		d.err = fmt.Errorf("extract: unknown values %#x in binary entity data", values)
+	}
+
+	if values&binaryScreenName != 0 {
+		t.screenName, t.screenNameIsSet = d.string(), true
+	}
+	if values&binaryHashtag != 0 {
+		t.hashtag, t.hashtagIsSet = d.string(), true
+	}
+	if values&binarySymbol != 0 {
+		t.cashtag, t.cashtagIsSet = d.string(), true
+	}
+	if values&binaryURLParts != 0 {
+		p := &t.urlParts
+		for _, s := range []*string{&p.Scheme, &p.Host, &p.Port, &p.Path, &p.Query, &p.Fragment} {
+			*s = d.string()
+		}
+		t.urlPartsIsSet = true
+	}
+	if values&binaryURLHosts != 0 {
+		t.urlParts.ASCIIHost = d.string()
+		t.urlParts.UnicodeHost = d.string()
+	}
+	if values&binaryHostSpoofing != 0 {
+		s := &t.hostSpoofing
+		s.Level = RestrictionLevel(d.int())
+		flags := d.uvarint()
+		s.MixedScript = flags&binaryMixedScript != 0
+		s.WholeScriptConfusable = flags&binaryWholeScriptConfusable != 0
+		s.Lookalike = d.string()
+		t.hostSpoofingIsSet = true
+	}
+	if values&binaryScreenNameKey != 0 {
+		t.screenNameKey, t.screenNameKeyIsSet = d.string(), true
+	}
+	if values&binaryHashtagKey != 0 {
+		t.hashtagKey, t.hashtagKeyIsSet = d.string(), true
+	}
+	if values&binaryMentionDomain != 0 {
+		t.mentionDomain, t.mentionDomainIsSet = d.string(), true
+	}
+	if values&binaryListSlug != 0 {
+		t.listSlug, t.listSlugIsSet = d.string(), true
+	}
+	return d.err
+}
+
+// finish returns an error if there is data left after decoding
+func (d *binaryDecoder) finish() error {
+	if d.err == nil && len(d.data) > 0 {
+		d.err = fmt.Errorf("extract: %d bytes of trailing binary entity data", len(d.data))
+	}
+	return d.err
+}
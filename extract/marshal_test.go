@@ -0,0 +1,128 @@
+package extract
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+const marshalText = "@username: #hashtag $TSLA http://user.example.com:8080/path?q=1#frag 🐱 example.com @alice@mastodon.social @owner/list-name"
+
+var marshalExtractor = func() *Extractor {
+	options := DefaultExtractorOptions()
+	options.FederatedMentions = true
+	return mustNewExtractor(options)
+}()
+
+func TestEntityJSON(t *testing.T) {
+	entities := marshalExtractor.Entities(marshalText)
+	if len(entities) != 7 {
+		t.Fatalf("Wrong number of entities. Expected:7 Got:%v", entities)
+	}
+
+	data, err := json.Marshal(entities)
+	if err != nil {
+		t.Fatalf("Error marshaling entities: %v", err)
+	}
+
+	var actual []*ByteEntity
+	if err := json.Unmarshal(data, &actual); err != nil {
+		t.Fatalf("Error unmarshaling entities: %v", err)
+	}
+	if !reflect.DeepEqual(actual, entities) {
+		t.Errorf("JSON did not round-trip. Expected:%#v Got:%#v", entities, actual)
+	}
+
+	expected := `{"type":"Mention","text":"@username","indices":[0,9],"byte_indices":[0,9],"utf16_indices":[0,9],"screen_name":"username","screen_name_key":"username"}`
+	if data, _ := json.Marshal(entities[0]); string(data) != expected {
+		t.Errorf("Incorrect JSON for mention. Expected:%s Got:%s", expected, data)
+	}
+
+	expected = `{"type":"Mention","text":"@alice@mastodon.social","indices":[83,105],"byte_indices":[86,108],"utf16_indices":[84,106],"screen_name":"alice","screen_name_key":"alice@mastodon.social","domain":"mastodon.social"}`
+	if data, _ := json.Marshal(entities[5]); string(data) != expected {
+		t.Errorf("Incorrect JSON for federated mention. Expected:%s Got:%s", expected, data)
+	}
+
+	// Entities stored by value are encoded the same way
+	byValue, err := json.Marshal(struct{ Entity ByteEntity }{*entities[5]})
+	if err != nil {
+		t.Fatalf("Error marshaling entity by value: %v", err)
+	}
+	if string(byValue) != `{"Entity":`+expected+`}` {
+		t.Errorf("Incorrect JSON for entity by value. Expected:%s Got:%s", expected, byValue)
+	}
+}
+
+func TestEntityJSONErrors(t *testing.T) {
+	for _, data := range []string{
+		`{"type":"Bogus","text":"x"}`,
+		`{"type":"Mention","indices":[0]}`,
+		`{"type":3.5}`,
+	} {
+		var e ByteEntity
+		if err := json.Unmarshal([]byte(data), &e); err == nil {
+			t.Errorf("Unmarshaling %s did not fail", data)
+		}
+	}
+
+	if _, err := json.Marshal(EntityType(99)); err == nil {
+		t.Errorf("Marshaling an unknown type did not fail")
+	}
+}
+
+func TestEntityBinary(t *testing.T) {
+	entities := marshalExtractor.Entities(marshalText)
+
+	for _, e := range entities {
+		data, err := e.MarshalBinary()
+		if err != nil {
+			t.Fatalf("Error marshaling %s: %v", e, err)
+		}
+
+		var actual ByteEntity
+		if err := actual.UnmarshalBinary(data); err != nil {
+			t.Fatalf("Error unmarshaling %s: %v", e, err)
+		}
+		if !reflect.DeepEqual(&actual, e) {
+			t.Errorf("Binary encoding did not round-trip. Expected:%#v Got:%#v", e, &actual)
+		}
+	}
+
+	data, err := MarshalEntities(entities)
+	if err != nil {
+		t.Fatalf("Error marshaling entities: %v", err)
+	}
+	actual, err := UnmarshalEntities(data)
+	if err != nil {
+		t.Fatalf("Error unmarshaling entities: %v", err)
+	}
+	if !reflect.DeepEqual(actual, entities) {
+		t.Errorf("Binary encoding did not round-trip. Expected:%v Got:%v", entities, actual)
+	}
+
+	// Every prefix of the data is invalid
+	for i := 0; i < len(data); i++ {
+		if _, err := UnmarshalEntities(data[:i]); err == nil {
+			t.Errorf("Unmarshaling %d of %d bytes did not fail", i, len(data))
+		}
+	}
+
+	if _, err := UnmarshalEntities(append(data, 0)); err == nil {
+		t.Errorf("Unmarshaling data with trailing bytes did not fail")
+	}
+
+	bad := append([]byte{}, data...)
+	bad[0] = binaryVersion + 1
+	if _, err := UnmarshalEntities(bad); err == nil {
+		t.Errorf("Unmarshaling an unknown version did not fail")
+	}
+
+	negative := *entities[0]
+	negative.UTF16Range = Range{-1, -1}
+	if _, err := negative.MarshalBinary(); err == nil {
+		t.Errorf("Marshaling a negative range did not fail")
+	}
+	if _, err := MarshalEntities([]*ByteEntity{entities[0], &negative}); err == nil {
+		t.Errorf("Marshaling entities with a negative range did not fail")
+	}
+}
@@ -0,0 +1,93 @@
+package extract
+
+import "unicode/utf8"
+
+// The functions below convert offsets into a string between bytes, runes
+// and UTF-16 code units (as used by JavaScript, Java and Objective-C
+// strings). Offsets past the end of the text are clamped to its length, and
+// offsets pointing into the middle of a character (a multi-byte UTF-8
+// sequence or a surrogate pair) are rounded down to the start of that
+// character. Invalid UTF-8 bytes count as one rune and one code unit each.
+
+// utf16Len returns the number of UTF-16 code units needed to encode text
+func utf16Len(text string) int {
+	n := 0
+	for _, r := range text {
+		n += utf16RuneLen(r)
+	}
+	return n
+}
+
+func utf16RuneLen(r rune) int {
+	if r >= 0x10000 && r <= utf8.MaxRune {
+		return 2
+	}
+	return 1
+}
+
+// ByteToRuneOffset converts a byte offset within text to a rune offset
+func ByteToRuneOffset(text string, offset int) int {
+	return utf8.RuneCountInString(text[:byteBoundary(text, offset)])
+}
+
+// ByteToUTF16Offset converts a byte offset within text to a UTF-16 code unit
+// offset
+func ByteToUTF16Offset(text string, offset int) int {
+	return utf16Len(text[:byteBoundary(text, offset)])
+}
+
+// RuneToByteOffset converts a rune offset within text to a byte offset
+func RuneToByteOffset(text string, offset int) int {
+	n := 0
+	for i := range text {
+		if n >= offset {
+			return i
+		}
+		n++
+	}
+	return len(text)
+}
+
+// RuneToUTF16Offset converts a rune offset within text to a UTF-16 code unit
+// offset
+func RuneToUTF16Offset(text string, offset int) int {
+	return ByteToUTF16Offset(text, RuneToByteOffset(text, offset))
+}
+
+// UTF16ToByteOffset converts a UTF-16 code unit offset within text to a byte
+// offset
+func UTF16ToByteOffset(text string, offset int) int {
+	n := 0
+	for i, r := range text {
+		n += utf16RuneLen(r)
+		if n > offset {
+			return i
+		}
+	}
+	return len(text)
+}
+
+// UTF16ToRuneOffset converts a UTF-16 code unit offset within text to a rune
+// offset
+func UTF16ToRuneOffset(text string, offset int) int {
+	return ByteToRuneOffset(text, UTF16ToByteOffset(text, offset))
+}
+
+// byteBoundary clamps offset to the bounds of text and moves it back to the
+// start of the character it points into
+func byteBoundary(text string, offset int) int {
+	if offset <= 0 {
+		return 0
+	}
+	if offset >= len(text) {
+		return len(text)
+	}
+	boundary := 0
+	for i := range text {
+		if i > offset {
+			break
+		}
+		boundary = i
+	}
+	return boundary
+}
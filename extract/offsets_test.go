@@ -0,0 +1,73 @@
+package extract
+
+import "testing"
+
+func TestOffsetConversions(t *testing.T) {
+	// "a" is 1 byte, "é" 2 bytes, "の" 3 bytes, "🐱" 4 bytes and a surrogate
+	// pair in UTF-16
+	text := "aéの🐱b"
+	tests := []struct {
+		bytes, runes, utf16 int
+	}{
+		{0, 0, 0},
+		{1, 1, 1},
+		{3, 2, 2},
+		{6, 3, 3},
+		{10, 4, 5},
+		{11, 5, 6},
+	}
+
+	for _, test := range tests {
+		if actual := ByteToRuneOffset(text, test.bytes); actual != test.runes {
+			t.Errorf("ByteToRuneOffset(%d) Expected:%d Got:%d", test.bytes, test.runes, actual)
+		}
+		if actual := ByteToUTF16Offset(text, test.bytes); actual != test.utf16 {
+			t.Errorf("ByteToUTF16Offset(%d) Expected:%d Got:%d", test.bytes, test.utf16, actual)
+		}
+		if actual := RuneToByteOffset(text, test.runes); actual != test.bytes {
+			t.Errorf("RuneToByteOffset(%d) Expected:%d Got:%d", test.runes, test.bytes, actual)
+		}
+		if actual := RuneToUTF16Offset(text, test.runes); actual != test.utf16 {
+			t.Errorf("RuneToUTF16Offset(%d) Expected:%d Got:%d", test.runes, test.utf16, actual)
+		}
+		if actual := UTF16ToByteOffset(text, test.utf16); actual != test.bytes {
+			t.Errorf("UTF16ToByteOffset(%d) Expected:%d Got:%d", test.utf16, test.bytes, actual)
+		}
+		if actual := UTF16ToRuneOffset(text, test.utf16); actual != test.runes {
+			t.Errorf("UTF16ToRuneOffset(%d) Expected:%d Got:%d", test.utf16, test.runes, actual)
+		}
+	}
+
+	// Offsets within a character round down, offsets outside the text clamp
+	clamped := []struct {
+		name             string
+		actual, expected int
+	}{
+		{"ByteToRuneOffset inside é", ByteToRuneOffset(text, 2), 1},
+		{"ByteToUTF16Offset inside 🐱", ByteToUTF16Offset(text, 8), 3},
+		{"UTF16ToByteOffset inside surrogate pair", UTF16ToByteOffset(text, 4), 6},
+		{"ByteToRuneOffset negative", ByteToRuneOffset(text, -1), 0},
+		{"RuneToByteOffset past end", RuneToByteOffset(text, 100), len(text)},
+		{"UTF16ToRuneOffset past end", UTF16ToRuneOffset(text, 100), 5},
+	}
+	for _, test := range clamped {
+		if test.actual != test.expected {
+			t.Errorf("%s Expected:%d Got:%d", test.name, test.expected, test.actual)
+		}
+	}
+}
+
+func TestEntityUTF16Range(t *testing.T) {
+	text := "🐱 @username #hashtag $TSLA 🐱 http://example.com"
+	expected := []Range{{3, 12}, {13, 21}, {22, 27}, {31, 49}}
+
+	entities := Entities(text)
+	if len(entities) != len(expected) {
+		t.Fatalf("Wrong number of entities. Expected:%d Got:%v", len(expected), entities)
+	}
+	for i, e := range entities {
+		if e.UTF16Range != expected[i] {
+			t.Errorf("Wrong UTF-16 range for %s. Expected:%s Got:%s", e.Text, expected[i], e.UTF16Range)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package extract
+
+import "sort"
+
+// OverlapPolicy selects which of two overlapping entities is kept
+type OverlapPolicy int
+
+// OverlapPolicies
+const (
+	// Keep the entity which starts first
+	KeepFirst OverlapPolicy = iota
+
+	// Keep the longer entity, or the one which starts first if both have
+	// the same length
+	KeepLongest
+
+	// Keep the entity whose type comes first in the priority order, or the
+	// one which starts first if both have the same priority
+	KeepPriority
+)
+
+// String implements the Stringer interface
+func (p OverlapPolicy) String() string {
+	switch p {
+	case KeepFirst:
+		return "KeepFirst"
+	case KeepLongest:
+		return "KeepLongest"
+	case KeepPriority:
+		return "KeepPriority"
+	}
+	return "Unknown"
+}
+
+// DefaultEntityPriority returns the priority order used by the KeepPriority
+// policy when no order is given: URL, Cashtag, List, Mention, Hashtag
+func DefaultEntityPriority() []EntityType {
+	return []EntityType{URL, Cashtag, List, Mention, Hashtag}
+}
+
+// overlapResolver removes overlapping entities following a policy
+type overlapResolver struct {
+	policy OverlapPolicy
+
+	// The rank of each type for KeepPriority, lower ranks first. Types
+	// which are not listed come last
+	priority map[EntityType]int
+}
+
+func newOverlapResolver(policy OverlapPolicy, priority []EntityType) overlapResolver {
+	if priority == nil {
+		priority = DefaultEntityPriority()
+	}
+	r := overlapResolver{policy: policy, priority: map[EntityType]int{}}
+	for i, t := range priority {
+		if _, ok := r.priority[t]; !ok {
+			r.priority[t] = i
+		}
+	}
+	return r
+}
+
+func (r overlapResolver) rank(t EntityType) int {
+	if rank, ok := r.priority[t]; ok {
+		return rank
+	}
+	return len(r.priority)
+}
+
+// prefers reports whether a should be kept rather than b when they overlap.
+// Neither is preferred when the policy does not tell them apart
+func (r overlapResolver) prefers(a, b *ByteEntity) bool {
+	switch r.policy {
+	case KeepLongest:
+		return a.Range.Length() > b.Range.Length()
+	case KeepPriority:
+		return r.rank(a.Type) < r.rank(b.Type)
+	}
+	return false
+}
+
+// resolve removes the entities which overlap another entity, choosing
+// between overlapping entities with the policy, and returns the others sorted
+// by start. Entities are considered from the most preferred to the least,
+// then by start, and each is kept unless it overlaps an entity already kept.
+// So an entity which was removed does not cause others to be removed
+func (r overlapResolver) resolve(entities entitiesT) entitiesT {
+	sort.Sort(entities)
+	preferred := append(entitiesT(nil), entities...)
+	sort.SliceStable(preferred, func(i, j int) bool {
+		return r.prefers(preferred[i], preferred[j])
+	})
+
+	var result entitiesT
+	for _, cur := range preferred {
+		i := sort.Search(len(result), func(i int) bool {
+			return result[i].Range.Start >= cur.Range.Start
+		})
+		if i > 0 && result[i-1].Range.Stop > cur.Range.Start ||
+			i < len(result) && result[i].Range.Start < cur.Range.Stop {
+			continue
+		}
+		result = append(result, nil)
+		copy(result[i+1:], result[i:])
+		result[i] = cur
+	}
+	return result
+}
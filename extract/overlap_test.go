@@ -0,0 +1,98 @@
+package extract
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestOverlapResolver(t *testing.T) {
+	entity := func(typ EntityType, start, stop int) *ByteEntity {
+		return &ByteEntity{
+			Text:  fmt.Sprintf("%v%v", typ, Range{start, stop}),
+			Range: Range{start, stop},
+			Type:  typ,
+		}
+	}
+	var (
+		hashtag = entity(Hashtag, 0, 5)
+		url     = entity(URL, 3, 12)
+		mention = entity(Mention, 10, 14)
+		cashtag = entity(Cashtag, 16, 20)
+
+		// A chain where each entity only overlaps its neighbours
+		chainHashtag = entity(Hashtag, 0, 5)
+		chainMention = entity(Mention, 4, 10)
+		chainURL     = entity(URL, 9, 20)
+	)
+
+	tests := []struct {
+		description string
+		policy      OverlapPolicy
+		priority    []EntityType
+		entities    []*ByteEntity
+		expected    []*ByteEntity
+	}{
+		{
+			"Keep first, compared with the last kept entity",
+			KeepFirst, nil,
+			[]*ByteEntity{mention, url, cashtag, hashtag},
+			[]*ByteEntity{hashtag, mention, cashtag},
+		},
+		{
+			"Keep longest",
+			KeepLongest, nil,
+			[]*ByteEntity{hashtag, url, mention, cashtag},
+			[]*ByteEntity{url, cashtag},
+		},
+		{
+			"Keep longest, first of the same length",
+			KeepLongest, nil,
+			[]*ByteEntity{entity(Mention, 0, 4), entity(Hashtag, 2, 6)},
+			[]*ByteEntity{entity(Mention, 0, 4)},
+		},
+		{
+			"Keep longest, restoring an entity which only overlapped a removed one",
+			KeepLongest, nil,
+			[]*ByteEntity{chainHashtag, chainMention, chainURL},
+			[]*ByteEntity{chainHashtag, chainURL},
+		},
+		{
+			"Keep priority, restoring an entity which only overlapped a removed one",
+			KeepPriority, nil,
+			[]*ByteEntity{chainURL, chainMention, chainHashtag},
+			[]*ByteEntity{chainHashtag, chainURL},
+		},
+		{
+			"Keep first in a chain",
+			KeepFirst, nil,
+			[]*ByteEntity{chainHashtag, chainMention, chainURL},
+			[]*ByteEntity{chainHashtag, chainURL},
+		},
+		{
+			"Default priority",
+			KeepPriority, nil,
+			[]*ByteEntity{hashtag, url, mention, cashtag},
+			[]*ByteEntity{url, cashtag},
+		},
+		{
+			"Custom priority",
+			KeepPriority, []EntityType{Mention, Hashtag},
+			[]*ByteEntity{hashtag, url, mention, cashtag},
+			[]*ByteEntity{hashtag, mention, cashtag},
+		},
+		{
+			"No overlaps",
+			KeepPriority, nil,
+			[]*ByteEntity{cashtag, hashtag, mention},
+			[]*ByteEntity{hashtag, mention, cashtag},
+		},
+	}
+
+	for _, test := range tests {
+		actual := newOverlapResolver(test.policy, test.priority).resolve(test.entities)
+		if fmt.Sprint(actual) != fmt.Sprint(test.expected) {
+			t.Errorf("Wrong entities kept for test [%s]. Expected:%v Got:%v",
+				test.description, test.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,621 @@
+package extract
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// The regular expressions which the scanner replaced, and the extraction
+// functions using them. They are kept as a reference implementation, to check
+// that the scanner finds exactly the same entities (See:
+// TestScannerMatchesReference) and to measure its speed (See:
+// benchmark_test.go)
+
+const (
+	refPunctuationChars = `!"#\$%&'\(\)\*\+,-\./:;<=>\?@\[\]\^_` + "`" + `\{\|\}~`
+
+	unicodeSpaces = "\u0009-\u000d" + //  # White_Space # Cc   [5] <control-0009>..<control-000D>
+		"\u0020" + // White_Space # Zs       SPACE
+		"\u0085" + // White_Space # Cc       <control-0085>
+		"\u00a0" + // White_Space # Zs       NO-BREAK SPACE
+		"\u1680" + // White_Space # Zs       OGHAM SPACE MARK
+		"\u180E" + // White_Space # Zs       MONGOLIAN VOWEL SEPARATOR
+		"\u2000-\u200a" + // # White_Space # Zs  [11] EN QUAD..HAIR SPACE
+		"\u2028" + // White_Space # Zl       LINE SEPARATOR
+		"\u2029" + // White_Space # Zp       PARAGRAPH SEPARATOR
+		"\u202F" + // White_Space # Zs       NARROW NO-BREAK SPACE
+		"\u205F" + // White_Space # Zs       MEDIUM MATHEMATICAL SPACE
+		"\u3000" // White_Space # Zs       IDEOGRAPHIC SPACE
+
+	unicodeSpacesSet = `[` + unicodeSpaces + `]`
+
+	controlChars = "\x00-\x1F\x7F"
+
+	invalidChars = "\uFFFE\uFEFF\uFFFF\u202A\u202B\u202C\u202D\u202E"
+
+	latinAccentChars = "\u00c0-\u00d6\u00d8-\u00f6\u00f8-\u00ff" + // Latin-1
+		"\u0100-\u024f" + // Latin Extended A and B
+		"\u0253\u0254\u0256\u0257\u0259\u025b\u0263\u0268\u026f\u0272\u0289\u028b" + // IPA Extensions
+		"\u02bb" + // Hawaiian
+		"\u0300-\u036f" + // Combining diacritics
+		"\u1e00-\u1eff" // Latin Extended Additional (mostly for Vietnamese)
+
+	//
+	// Hashtag
+	//
+
+	hashtagAlphaChars           = `\p{L}\p{M}`
+	hashtagAlphaSet             = `[` + hashtagAlphaChars + `]`
+	hashtagNumericChars         = `\p{Nd}`
+	hashtagAlphaNumericSet      = `[` + hashtagAlphaChars + hashtagNumericChars + hashtagSpecialChars + `]`
+	hashtagBoundaryInvalidChars = `&` + hashtagAlphaChars + hashtagNumericChars + hashtagSpecialChars
+	hashtagBoundary             = `^|$|[^` + hashtagBoundaryInvalidChars + `]`
+
+	//
+	// URL
+	//
+
+	urlValidPrecedingChars = `(?:[^[:alnum:]@＠$#＃` + "\u202A-\u202E]|^)"
+	urlValidChars          = `[^` + refPunctuationChars + `[:space:][:cntrl:]` + invalidChars + unicodeSpaces + `]`
+	urlValidSubDomain      = `(?:(?:` + urlValidChars + `(?:[_-]|` + urlValidChars + `*)*)?` + urlValidChars + `\.)`
+	urlValidDomainName     = `(?:(?:` + urlValidChars + `(?:[-]|` + urlValidChars + `*)*)?` + urlValidChars + `\.)`
+
+	urlPunyCode = `(?:xn--[0-9a-z]+)`
+
+	urlValidSpecialCCTLD = `(?:co|tv)`
+
+	urlValidPortNumber = `[0-9]+`
+
+	urlValidGeneralPathChars = `[a-z0-9!\*';:=\+,\.\$/%#\[\]\-_~\|&@` + latinAccentChars + `]`
+
+	urlBalancedParens = `\(` + urlValidGeneralPathChars + `+\)`
+
+	urlValidPathEndingChars = `[a-z0-9=_#/\-\+` + latinAccentChars + `]|(?:` + urlBalancedParens + `)`
+
+	urlValidPath = `(?:` +
+		`(?:` +
+		urlValidGeneralPathChars + `*` +
+		`(?:` + urlBalancedParens + urlValidGeneralPathChars + `*)*` +
+		urlValidPathEndingChars +
+		`)|(?:@` + urlValidGeneralPathChars + `+/)` +
+		`)`
+
+	urlValidURLQueryChars       = `[a-z0-9!\?\*'\(\);:&=\+\$/%#\[\]\-_\.,~\|@]`
+	urlValidURLQueryEndingChars = `[a-z0-9_&=#/]`
+
+	//
+	// Cashtag
+	//
+
+	cashtagSymbol = `[a-z]{1,6}`
+	cashtagSuffix = `[._][a-z]{1,2}`
+
+	// Capturing groups
+	validHashtagGroupHash = 1
+	validHashtagGroupTag  = 2
+
+	validCashtagGroupDollar = 1
+	validCashtagGroupSymbol = 2
+	validCashtagGroupSuffix = 3
+
+	validMentionGroupBefore   = 1
+	validMentionGroupAt       = 2
+	validMentionGroupUsername = 3
+
+	validURLGroupAll         = 1
+	validURLGroupBefore      = 2
+	validURLGroupURL         = 3
+	validURLGroupProtocol    = 4
+	validURLGroupDomain      = 5
+	validURLGroupPort        = 6
+	validURLGroupPath        = 7
+	validURLGroupQueryString = 8
+)
+
+var (
+	// Hash tag
+	validHashtag           = regexp.MustCompile(`(?i)(?:` + hashtagBoundary + `)` + `([#＃])(` + hashtagAlphaNumericSet + `*` + hashtagAlphaSet + hashtagAlphaNumericSet + `*)`)
+	invalidHashtagMatchEnd = regexp.MustCompile(`\A(?:[#＃]|://)`)
+	rtlCharacters          = regexp.MustCompile("[\u0600-\u06FF\u0750-\u077F\u0590-\u05FF\uFE70-\uFEFF]")
+
+	// Cashtags
+	validCashtag         = regexp.MustCompile(`(?i)(?:^|[` + unicodeSpaces + `])(\$)(` + cashtagSymbol + `)(` + cashtagSuffix + `)?`)
+	validCashtagMatchEnd = regexp.MustCompile(`\A(?:$|[` + unicodeSpaces + refPunctuationChars + `])`)
+
+	// Mentions
+	atSigns = regexp.MustCompile(`[` + atSignChars + `]`)
+
+	invalidMentionMatchEnd = regexp.MustCompile(`\A(?:[` + atSignChars + latinAccentChars + `]|://)`)
+	validListSlug          = regexp.MustCompile(`\A/([a-zA-Z][a-zA-Z0-9_\-]*)`)
+
+	// URLs
+	validTcoURL                         = regexp.MustCompile(`(?i)^https?://t\.co\/[a-z0-9]+`)
+	validSpecialShortDomain             = regexp.MustCompile(`\A` + urlValidDomainName + urlValidSpecialCCTLD + `\z`)
+	invalidURLWithoutProtocolMatchBegin = regexp.MustCompile(`[\-_\./]$`)
+)
+
+// The patterns below depend on the options of an Extractor, so they are
+// assembled and compiled by NewExtractor rather than at package init.
+
+// validMentionPattern returns the mention pattern allowing at most one of
+// the given separator characters between the alphanumeric parts of a
+// username
+func validMentionPattern(separators string) string {
+	username := `[a-z0-9]+`
+	if separators != "" {
+		username += `(?:` + charClass(separators) + `[a-z0-9]+)?`
+	}
+	return `(?i)([^a-zA-Z0-9_!#$%&*` + atSignChars + `]|^|^\s*RT:?)([` + atSignChars + `]+)(` + username + `)?`
+}
+
+// tldPattern returns an alternation matching any of the given TLDs. Longer
+// TLDs are listed first so that a TLD which is a prefix of another never
+// shadows it
+func tldPattern(tlds []string) string {
+	sorted := make([]string, len(tlds))
+	copy(sorted, tlds)
+	sort.Slice(sorted, func(i, j int) bool {
+		if len(sorted[i]) != len(sorted[j]) {
+			return len(sorted[i]) > len(sorted[j])
+		}
+		return sorted[i] < sorted[j]
+	})
+
+	quoted := make([]string, len(sorted))
+	for i, tld := range sorted {
+		quoted[i] = regexp.QuoteMeta(tld)
+	}
+	return `(?:` + strings.Join(quoted, `|`) + `)`
+}
+
+func urlValidDomain(gTLD, ccTLD string) string {
+	return `(?:` +
+		urlValidSubDomain + `*` + urlValidDomainName +
+		`(?:` + gTLD + `|` + ccTLD + `|` + urlPunyCode + `)` +
+		`)`
+}
+
+func urlValidASCIIDomain(gTLD, ccTLD string) string {
+	return `(?:` +
+		`(?:[[:alnum:]][[:alnum:]_\-` + latinAccentChars + `]*)+\.)+` +
+		`(?:` + gTLD + `|` + ccTLD + `|` + urlPunyCode + `)`
+}
+
+func validURLPattern(gTLD, ccTLD string) string {
+	return `(` + //  $1 total match
+		`(` + urlValidPrecedingChars + `)` + //  $2 Preceding character
+		`(` + //  $3 URL
+		`(https?://)?` + //  $4 Protocol (optional)
+		`(` + urlValidDomain(gTLD, ccTLD) + `)` + //  $5 Domain(s)
+		`(?::(` + urlValidPortNumber + `))?` + //  $6 Port number (optional)
+		`(/` +
+		urlValidPath + `*` +
+		`)?` + //  $7 URL Path and anchor
+		`(\?` + urlValidURLQueryChars + `*` + //  $8 Query String
+		urlValidURLQueryEndingChars + `)?` +
+		`)(?:[^[:alnum:]@]|$)` +
+		`)`
+}
+
+// charClass returns a character class matching any of the characters in
+// chars
+func charClass(chars string) string {
+	var b strings.Builder
+	b.WriteString(`[`)
+	for _, r := range chars {
+		fmt.Fprintf(&b, `\x{%x}`, r)
+	}
+	b.WriteString(`]`)
+	return b.String()
+}
+
+// referenceExtractor extracts entities with the reference implementation
+type referenceExtractor struct {
+	*Extractor
+
+	validMention       *regexp.Regexp
+	validURL           *regexp.Regexp
+	validASCIIDomain   *regexp.Regexp
+	invalidShortDomain *regexp.Regexp
+}
+
+func newReferenceExtractor(options ExtractorOptions) (*referenceExtractor, error) {
+	extractor, err := NewExtractor(options)
+	if err != nil {
+		return nil, err
+	}
+	x := &referenceExtractor{Extractor: extractor}
+	registry := options.TLDs
+	if registry == nil {
+		registry = defaultTLDRegistry
+	}
+	gTLD := referenceTLDPattern(options.GenericTLDs, registry.generic)
+	ccTLD := referenceTLDPattern(options.CountryTLDs, registry.country)
+
+	if x.validMention, err = regexp.Compile(
+		validMentionPattern(options.UsernameSeparators),
+	); err != nil {
+		return nil, err
+	}
+	if x.validURL, err = regexp.Compile(
+		`(?i)` + validURLPattern(gTLD, ccTLD),
+	); err != nil {
+		return nil, err
+	}
+	if x.validASCIIDomain, err = regexp.Compile(
+		urlValidASCIIDomain(gTLD, ccTLD),
+	); err != nil {
+		return nil, err
+	}
+	if x.invalidShortDomain, err = regexp.Compile(
+		`\A` + urlValidDomainName + ccTLD + `\z`,
+	); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// referenceTLDPattern returns the pattern for a TLD list option, falling back
+// to the registry's list when the option is nil
+func referenceTLDPattern(tlds []string, registry []string) string {
+	if tlds == nil {
+		tlds = registry
+	}
+	if len(tlds) == 0 {
+		// An empty character class never matches
+		return `[^\x00-\x{10FFFF}]`
+	}
+	return tldPattern(tlds)
+}
+
+func (x *referenceExtractor) Entities(text string) []*ByteEntity {
+	var result entitiesT
+	result = x.URLs(text)
+	result = append(result, x.Hashtags(text)...)
+	result = append(result, x.MentionsOrLists(text)...)
+	result = append(result, x.Cashtags(text)...)
+
+	result = x.overlaps.resolve(result)
+
+	// Overlaps are resolved before filtering so that disabling a type does
+	// not change which entities of the other types are found
+	if x.entityTypes != nil {
+		n := 0
+		for _, e := range result {
+			if x.returnsType(e.Type) {
+				result[n] = e
+				n++
+			}
+		}
+		result = result[:n]
+	}
+	return result
+}
+
+func (x *referenceExtractor) URLs(text string) []*ByteEntity {
+	// This giant pile of barf is copied from the various twitter-text
+	// implementations. There must be a better way!
+	var result entitiesT
+	var (
+		matchStart     int
+		matchEnd       int
+		precedingStart int
+		precedingEnd   int
+		domainStart    int
+		domainEnd      int
+		pathStart      int
+		pathEnd        int
+	)
+
+	// Start at the beginning of the input string, walking forward one match at
+	// a time. We have to walk the string because the regexp package lacks
+	// support for lookahead assertions
+	offset := 0
+	nextOffset := 0
+	for {
+		offset = nextOffset
+		substr := text[offset:]
+		match := x.validURL.FindStringSubmatchIndex(substr)
+
+		// If no matches are found in this portion of the string, we're done
+		if match == nil {
+			break
+		}
+
+		// Next time around, start at the end of the current match, minus 1
+		// because indices are not inclusive
+		nextOffset = match[1] + offset - 1
+
+		matchStart = match[validURLGroupURL*2]
+		matchEnd = match[validURLGroupURL*2+1]
+
+		// If protocol is missing, only extract ascii domains
+		if match[validURLGroupProtocol*2] < 0 {
+			if !x.extractURLsWithoutProtocol {
+				continue
+			}
+
+			var lastEntity *ByteEntity
+			lastInvalid := false
+			precedingStart = match[validURLGroupBefore*2]
+			precedingEnd = match[validURLGroupBefore*2+1]
+			domainStart = match[validURLGroupDomain*2]
+			domainEnd = match[validURLGroupDomain*2+1]
+			pathStart = match[validURLGroupPath*2]
+			pathEnd = match[validURLGroupPath*2+1]
+
+			// check for invalid preceding character
+			if invalidURLWithoutProtocolMatchBegin.MatchString(
+				substr[precedingStart:precedingEnd],
+			) {
+				continue
+			}
+
+			// Make sure the protocol-less domain is ascii only
+			// e.g., in the case of "한국twitter.com", only extract twitter.com
+			if m := x.validASCIIDomain.FindStringSubmatchIndex(
+				substr[domainStart:domainEnd],
+			); m != nil {
+				lastEntity = &ByteEntity{
+					Text: substr[matchStart+m[0] : matchStart+m[1]],
+					ByteRange: Range{
+						Start: matchStart + offset + m[0],
+						Stop:  matchStart + offset + m[1]},
+					Type:          URL,
+					urlParts:      newURLParts(substr, noRange, Range{matchStart + m[0], matchStart + m[1]}, noRange, noRange, noRange),
+					urlPartsIsSet: true}
+
+				// Set the next offset to the end of this match
+				nextOffset = matchStart + m[1] + offset - 1
+
+				// If the url has a Generic TLD (not CC TLD), it's valid
+				if lastInvalid = x.invalidShortDomain.MatchString(
+					lastEntity.Text,
+				); !lastInvalid {
+					result = append(result, lastEntity)
+				}
+			}
+
+			if lastEntity == nil {
+				continue
+			}
+
+			// If the match contains a path immediately following the domain,
+			// append it to the match
+			if pathStart > 0 && pathStart == lastEntity.ByteRange.Stop-offset {
+				// If the last result was invalid b/c it did not contain a GTLD,
+				// append it
+				if lastInvalid {
+					result = append(result, lastEntity)
+				}
+
+				// Update the text and offsets
+				lastEntity.Text += substr[pathStart:pathEnd]
+				lastEntity.urlParts = newURLParts(
+					lastEntity.Text,
+					Range{-1, -1},
+					Range{0, len(lastEntity.urlParts.Host)},
+					Range{-1, -1},
+					Range{len(lastEntity.urlParts.Host), len(lastEntity.Text)},
+					Range{-1, -1},
+				)
+				lastEntity.ByteRange.Stop = pathEnd + offset
+				nextOffset = lastEntity.ByteRange.Stop - 1
+			} else if validSpecialShortDomain.MatchString(lastEntity.Text) {
+				result = append(result, lastEntity)
+			}
+		} else {
+			// Else, the url contains a protocol
+			url := substr[matchStart:matchEnd]
+			// If it's a t.co url, restrict to certain path characters
+			if tcoLoc := validTcoURL.FindStringIndex(url); tcoLoc != nil {
+				url = url[tcoLoc[0]:tcoLoc[1]]
+				matchEnd = matchStart + len(url)
+			}
+			// Keep the components captured by the match, clipped to the end
+			// of the (possibly shortened) URL
+			group := func(n int) Range {
+				start, stop := match[n*2], match[n*2+1]
+				if start < 0 || start >= matchEnd {
+					return Range{-1, -1}
+				}
+				if stop > matchEnd {
+					stop = matchEnd
+				}
+				return Range{start, stop}
+			}
+			result = append(result,
+				&ByteEntity{Text: url,
+					ByteRange: Range{
+						Start: matchStart + offset,
+						Stop:  matchEnd + offset},
+					Type: URL,
+					urlParts: newURLParts(
+						substr,
+						group(validURLGroupProtocol),
+						group(validURLGroupDomain),
+						group(validURLGroupPort),
+						group(validURLGroupPath),
+						group(validURLGroupQueryString),
+					),
+					urlPartsIsSet: true})
+		}
+	}
+
+	for _, e := range result {
+		if host := e.urlParts.UnicodeHost; host != "" {
+			e.hostSpoofing, e.hostSpoofingIsSet = x.protectedDomains.check(host), true
+		}
+	}
+
+	// Add character/rune offsets in addition to byte offsets
+	result.fixIndices(text)
+	return result
+}
+
+func (x *referenceExtractor) Mentions(text string) []*ByteEntity {
+	var result []*ByteEntity
+	for _, e := range x.MentionsOrLists(text) {
+		if e.Type == Mention {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+func (x *referenceExtractor) MentionsOrLists(text string) []*ByteEntity {
+	// Optimization
+	if !strings.ContainsAny(text, "@＠") {
+		return nil
+	}
+
+	var result entitiesT
+	matches := x.validMention.FindAllStringSubmatchIndex(text, -1)
+	for _, m := range matches {
+		matchEnd := text[m[1]:]
+		if invalidMentionMatchEnd.MatchString(matchEnd) {
+			continue
+		}
+
+		atSignStart := m[validMentionGroupAt*2]
+		screennameStart := m[validMentionGroupUsername*2]
+		screennameEnd := m[validMentionGroupUsername*2+1]
+
+		if screennameEnd-screennameStart < x.minUsernameLength ||
+			screennameEnd-screennameStart > x.maxUsernameLength {
+			continue
+		}
+
+		start := atSignStart
+		stop := screennameEnd
+
+		screenName := text[screennameStart:screennameEnd]
+		entity := &ByteEntity{
+			Text:               text[start:stop],
+			screenName:         screenName,
+			screenNameIsSet:    true,
+			screenNameKey:      x.ScreenNameKey(screenName),
+			screenNameKeyIsSet: true,
+			ByteRange: Range{
+				Start: start,
+				Stop:  stop,
+			},
+			Type: Mention,
+		}
+		if l := validListSlug.FindStringSubmatchIndex(text[stop:]); l != nil &&
+			l[3]-l[2] <= maxListSlugLength && !invalidMentionMatchEnd.MatchString(text[stop+l[1]:]) {
+			entity.Type = List
+			entity.listSlug, entity.listSlugIsSet = text[stop+l[2]:stop+l[3]], true
+			entity.Text = text[start : stop+l[1]]
+			entity.ByteRange.Stop = stop + l[1]
+		}
+		result = append(result, entity)
+	}
+
+	result.fixIndices(text)
+	return result
+}
+
+func (x *referenceExtractor) Hashtags(text string) []*ByteEntity {
+	return x.extractHashtags(text, true)
+}
+
+func (x *referenceExtractor) extractHashtags(text string, checkURLOverlap bool) []*ByteEntity {
+	// Optimization
+	if !strings.ContainsAny(text, "#＃") {
+		return nil
+	}
+	var result entitiesT
+	var hashStart int
+	var hashtagStart int
+	var hashtagEnd int
+	for _, match := range validHashtag.FindAllStringSubmatchIndex(text, -1) {
+		if invalidHashtagMatchEnd.MatchString(text[match[1]:]) {
+			continue
+		}
+		hashStart = match[validHashtagGroupHash*2]
+		hashtagStart = match[validHashtagGroupTag*2]
+		hashtagEnd = match[validHashtagGroupTag*2+1]
+		hashtag := text[hashtagStart:hashtagEnd]
+		result = append(result, &ByteEntity{
+			Text:            text[hashStart:hashtagEnd],
+			hashtag:         hashtag,
+			hashtagIsSet:    true,
+			hashtagKey:      x.HashtagKey(hashtag),
+			hashtagKeyIsSet: true,
+			ByteRange: Range{
+				Start: hashStart,
+				Stop:  hashtagEnd,
+			},
+			Type: Hashtag,
+		})
+	}
+
+	result.fixIndices(text)
+
+	if checkURLOverlap {
+		urls := x.URLs(text)
+		result = append(result, urls...)
+		result = x.overlaps.resolve(result)
+
+		numHashtags := 0
+		for _, e := range result {
+			if e.Type == Hashtag {
+				result[numHashtags] = e
+				numHashtags++
+			}
+		}
+		result = result[:numHashtags]
+
+		var tmpResult []*ByteEntity
+		for _, e := range result {
+			if e.Type == Hashtag {
+				tmpResult = append(tmpResult, e)
+			}
+		}
+		result = tmpResult
+	}
+
+	return result
+}
+
+func (x *referenceExtractor) Cashtags(text string) []*ByteEntity {
+	// Optimization
+	if !strings.Contains(text, "$") {
+		return nil
+	}
+
+	var result entitiesT
+	for _, m := range validCashtag.FindAllStringSubmatchIndex(text, -1) {
+		dollarStart := m[validCashtagGroupDollar*2]
+		symbolStart := m[validCashtagGroupSymbol*2]
+		symbolEnd := m[validCashtagGroupSymbol*2+1]
+
+		// The regexp package lacks lookahead assertions, so the character
+		// following the match is checked here. If the match with its suffix
+		// (e.g. "$BRK.A") is not properly terminated, fall back to the bare
+		// symbol, which is always followed by the suffix's '.' or '_'
+		if suffixEnd := m[validCashtagGroupSuffix*2+1]; suffixEnd > 0 &&
+			validCashtagMatchEnd.MatchString(text[suffixEnd:]) {
+			symbolEnd = suffixEnd
+		} else if suffixEnd < 0 && !validCashtagMatchEnd.MatchString(text[symbolEnd:]) {
+			continue
+		}
+
+		result = append(result, &ByteEntity{
+			Text:         text[dollarStart:symbolEnd],
+			cashtag:      text[symbolStart:symbolEnd],
+			cashtagIsSet: true,
+			ByteRange: Range{
+				Start: dollarStart,
+				Stop:  symbolEnd,
+			},
+			Type: Cashtag,
+		})
+	}
+
+	result.fixIndices(text)
+	return result
+}
@@ -0,0 +1,81 @@
+package extract
+
+import "strings"
+
+// ReplyScreenName returns the screen name a post replies to, using the
+// default username rules. See Extractor.ReplyScreenName
+func ReplyScreenName(text string) (string, bool) {
+	return defaultExtractor.ReplyScreenName(text)
+}
+
+// ReplyScreenName returns the screen name a post replies to and a boolean
+// indicating whether the post is a reply. A post is a reply when it starts
+// with a mention, possibly preceded by whitespace, e.g. "@username hello".
+// The return value will be ("", false) for posts which do not start with a
+// mention, including lists and retweets
+func (x *Extractor) ReplyScreenName(text string) (string, bool) {
+	mentions := x.ReplyMentions(text)
+	if len(mentions) == 0 {
+		return "", false
+	}
+	return mentions[0].ScreenName()
+}
+
+// ReplyMentions returns the reply prefix of a post, using the default
+// username rules. See Extractor.ReplyMentions
+func ReplyMentions(text string) []*ByteEntity {
+	return defaultExtractor.ReplyMentions(text)
+}
+
+// ReplyMentions returns the mentions at the start of a post which are only
+// separated by whitespace, e.g. "@alice" and "@bob" in "@alice @bob hello
+// @carol". These are the accounts a reply is addressed to. The run of
+// mentions stops at the first list or other text
+func (x *Extractor) ReplyMentions(text string) []*ByteEntity {
+	var result entitiesT
+	offset := 0
+	for _, e := range x.scan(text, scanMentions).mentions {
+		if e.Type != Mention || !isSpaces(text[offset:e.ByteRange.Start]) {
+			break
+		}
+		result = append(result, e)
+		offset = e.ByteRange.Stop
+	}
+	x.annotate(result)
+	return result
+}
+
+// RetweetScreenName returns the screen name of the account a post retweets,
+// using the default username rules. See Extractor.RetweetScreenName
+func RetweetScreenName(text string) (string, bool) {
+	return defaultExtractor.RetweetScreenName(text)
+}
+
+// RetweetScreenName returns the screen name of the account a post retweets
+// and a boolean indicating whether the post is a retweet. A post is a
+// retweet when it starts with an "RT" or "RT:" marker followed by a mention,
+// possibly with whitespace before and after the marker, e.g.
+// "RT @username: text". The marker is case-insensitive
+func (x *Extractor) RetweetScreenName(text string) (string, bool) {
+	rest := strings.TrimLeftFunc(text, isUnicodeSpace)
+	if len(rest) < 2 || rest[0] != 'R' && rest[0] != 'r' || rest[1] != 'T' && rest[1] != 't' {
+		return "", false
+	}
+	rest = strings.TrimPrefix(rest[2:], ":")
+	start := len(text) - len(strings.TrimLeftFunc(rest, isUnicodeSpace))
+
+	for _, e := range x.scan(text, scanMentions).mentions {
+		if e.ByteRange.Start == start && e.Type == Mention {
+			return e.screenName, true
+		}
+		if e.ByteRange.Start >= start {
+			break
+		}
+	}
+	return "", false
+}
+
+// isSpaces reports whether s only contains whitespace
+func isSpaces(s string) bool {
+	return strings.TrimLeftFunc(s, isUnicodeSpace) == ""
+}
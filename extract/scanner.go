@@ -0,0 +1,846 @@
+package extract
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// scanKinds selects the entity types a scan looks for
+type scanKinds uint
+
+const (
+	scanURLs scanKinds = 1 << iota
+	scanHashtags
+	scanMentions
+	scanCashtags
+
+	scanAll = scanURLs | scanHashtags | scanMentions | scanCashtags
+)
+
+// scanner finds the entities of a text in a single pass, trying at each
+// character the rules of the entity types which may start there.
+//
+// The rules were first written as regular expressions, and the scanner keeps
+// their exact semantics (See: the reference implementation in
+// reference_test.go), including the way the expressions were applied: each
+// entity type resumes after the end of its previous match, so entities of
+// different types may overlap until the overlaps are resolved, and URLs are
+// searched for again from the last character of each match, as with a
+// lookahead assertion.
+type scanner struct {
+	x     *Extractor
+	text  string
+	kinds scanKinds
+
+	// The offsets from which the search for each entity type resumes
+	urlOffset     int
+	hashtagOffset int
+	mentionOffset int
+	cashtagOffset int
+
+	urls     entitiesT
+	hashtags entitiesT
+	mentions entitiesT
+	cashtags entitiesT
+
+	// Scratch space for the labels of a domain
+	labels []domainLabel
+
+	// The last run of domain label characters, which is shared by all the
+	// URL candidates starting within it
+	run labelRun
+}
+
+// labelRun is a run of characters which may appear in a domain label
+type labelRun struct {
+	start, end int
+
+	// Whether the last character may end a label
+	validEnd bool
+
+	// The offset of the last underscore, or -1
+	underscore int
+}
+
+// domainLabel is a label of a domain candidate, followed by a dot
+type domainLabel struct {
+	end        int
+	underscore bool
+}
+
+// urlMatch holds the byte ranges of the components of a URL candidate.
+// Components which are absent have a negative start
+type urlMatch struct {
+	before   Range // The preceding character, which may be empty
+	url      Range
+	protocol Range
+	domain   Range
+	port     Range
+	path     Range
+	query    Range
+
+	// The end of the candidate, including the character following the URL
+	end int
+}
+
+var noRange = Range{-1, -1}
+
+func (x *Extractor) scan(text string, kinds scanKinds) *scanner {
+	s := &scanner{x: x, text: text, kinds: kinds}
+	prev, prevStart := utf8.RuneError, -1
+	for p := 0; p < len(text); {
+		r, w := utf8.DecodeRuneInString(text[p:])
+		if kinds&scanURLs != 0 {
+			s.scanURL(p, w)
+		}
+		switch {
+		case isHashSign(r) && kinds&scanHashtags != 0:
+			s.scanHashtag(p, w, prev, prevStart)
+		case isAtSign(r) && kinds&scanMentions != 0:
+			s.scanMention(p, prev, prevStart)
+		case r == '$' && kinds&scanCashtags != 0:
+			s.scanCashtag(p, prev, prevStart)
+		}
+		prev, prevStart = r, p
+		p += w
+	}
+
+	s.urls.fixIndices(text)
+	s.hashtags.fixIndices(text)
+	s.mentions.fixIndices(text)
+	s.cashtags.fixIndices(text)
+	return s
+}
+
+// decode returns the rune at offset i, and its width
+func (s *scanner) decode(i int) (rune, int) {
+	return utf8.DecodeRuneInString(s.text[i:])
+}
+
+//
+// Hashtags
+//
+
+func (s *scanner) scanHashtag(p, w int, prev rune, prevStart int) {
+	if p > 0 && (prevStart < s.hashtagOffset || prev == '&' || IsHashtagCharacter(prev)) {
+		return
+	}
+
+	end, alpha := p+w, false
+	for end < len(s.text) {
+		r, w := s.decode(end)
+		if !IsHashtagCharacter(r) {
+			break
+		}
+		alpha = alpha || isHashtagAlpha(r)
+		end += w
+	}
+	if !alpha {
+		return
+	}
+
+	s.hashtagOffset = end
+	if rest := s.text[end:]; strings.HasPrefix(rest, "://") ||
+		rest != "" && isHashSign(firstRune(rest)) {
+		return
+	}
+	s.hashtags = append(s.hashtags, &ByteEntity{
+		Text:         s.text[p:end],
+		hashtag:      s.text[p+w : end],
+		hashtagIsSet: true,
+		ByteRange:    Range{Start: p, Stop: end},
+		Type:         Hashtag,
+	})
+}
+
+// hashtagsOutsideURLs returns the hashtags which are kept when resolving
+// their overlaps with URLs
+func (s *scanner) hashtagsOutsideURLs() entitiesT {
+	if len(s.hashtags) == 0 {
+		return nil
+	}
+
+	var all entitiesT
+	all = append(all, s.hashtags...)
+	all = append(all, s.urls...)
+	var result entitiesT
+	for _, e := range s.x.overlaps.resolve(all) {
+		if e.Type == Hashtag {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+//
+// Mentions
+//
+
+func (s *scanner) scanMention(p int, prev rune, prevStart int) {
+	if p > 0 && (prevStart < s.mentionOffset || !isMentionPrecedingChar(prev)) &&
+		!(s.mentionOffset == 0 && isRetweetPrefix(s.text[:p])) {
+		return
+	}
+
+	at := p
+	for at < len(s.text) {
+		r, w := s.decode(at)
+		if !isAtSign(r) {
+			break
+		}
+		at += w
+	}
+	end := s.alnumRun(at)
+	if end > at && end < len(s.text) && s.x.usernameSeparators != "" {
+		if r, w := s.decode(end); strings.ContainsRune(s.x.usernameSeparators, r) {
+			if next := s.alnumRun(end + w); next > end+w {
+				end = next
+			}
+		}
+	}
+
+	s.mentionOffset = end
+	domain, domainEnd := s.matchMentionDomain(end)
+	if domain < 0 && !s.isMentionEnd(end) {
+		return
+	}
+	if n := end - at; n < s.x.minUsernameLength || n > s.x.maxUsernameLength {
+		return
+	}
+	mention := &ByteEntity{
+		Text:            s.text[p:end],
+		screenName:      s.text[at:end],
+		screenNameIsSet: true,
+		ByteRange:       Range{Start: p, Stop: end},
+		Type:            Mention,
+	}
+	if domain >= 0 {
+		s.mentionOffset = domainEnd
+		mention.Text = s.text[p:domainEnd]
+		mention.ByteRange.Stop = domainEnd
+		mention.mentionDomain, mention.mentionDomainIsSet = s.text[domain:domainEnd], true
+	} else if slug, slugEnd := s.matchListSlug(end); slug >= 0 {
+		s.mentionOffset = slugEnd
+		mention.Type = List
+		mention.Text = s.text[p:slugEnd]
+		mention.ByteRange.Stop = slugEnd
+		mention.listSlug, mention.listSlugIsSet = s.text[slug:slugEnd], true
+	}
+	s.mentions = append(s.mentions, mention)
+}
+
+// The maximum length of a list slug, without the leading slash
+const maxListSlugLength = 25
+
+// matchListSlug matches the "/slug" of a list following the username ending
+// at i. A slug starts with an ASCII letter, followed by ASCII letters, digits,
+// underscores and hyphens, and is at most maxListSlugLength long. Returns the
+// start and end of the slug, or a negative start if there is none, in which
+// case the username is a mention on its own
+func (s *scanner) matchListSlug(i int) (int, int) {
+	if i+1 >= len(s.text) || s.text[i] != '/' || !isASCIILetter(rune(s.text[i+1])) {
+		return -1, -1
+	}
+	start := i + 1
+	end := start
+	for end < len(s.text) && (isASCIIAlnum(rune(s.text[end])) || s.text[end] == '_' || s.text[end] == '-') {
+		end++
+	}
+	if end-start > maxListSlugLength || !s.isMentionEnd(end) {
+		return -1, -1
+	}
+	return start, end
+}
+
+// isMentionEnd reports whether a mention may end at i
+func (s *scanner) isMentionEnd(i int) bool {
+	rest := s.text[i:]
+	if rest == "" {
+		return true
+	}
+	r := firstRune(rest)
+	return !strings.HasPrefix(rest, "://") && !isAtSign(r) && !isLatinAccent(r)
+}
+
+// matchMentionDomain matches the "@domain" of a federated mention following
+// the username ending at i, with the domain rules of URLs. Returns the start
+// and end of the domain, or a negative start if there is none
+func (s *scanner) matchMentionDomain(i int) (int, int) {
+	if !s.x.federatedMentions || i >= len(s.text) {
+		return -1, -1
+	}
+	r, w := s.decode(i)
+	if !isAtSign(r) {
+		return -1, -1
+	}
+	if end, ok := s.matchDomain(i + w); ok && s.isMentionEnd(end) {
+		return i + w, end
+	}
+	return -1, -1
+}
+
+// isRetweetPrefix reports whether prefix is an "RT" or "RT:" retweet marker,
+// possibly preceded by whitespace, which may directly precede a mention
+func isRetweetPrefix(prefix string) bool {
+	prefix = strings.TrimLeft(prefix, "\t\n\f\r ")
+	if strings.HasSuffix(prefix, ":") {
+		prefix = prefix[:len(prefix)-1]
+	}
+	return len(prefix) == 2 && (prefix[0] == 'R' || prefix[0] == 'r') &&
+		(prefix[1] == 'T' || prefix[1] == 't')
+}
+
+// alnumRun returns the end of the run of letters and digits starting at i
+func (s *scanner) alnumRun(i int) int {
+	for i < len(s.text) {
+		r, w := s.decode(i)
+		if !isAlnum(r) {
+			break
+		}
+		i += w
+	}
+	return i
+}
+
+//
+// Cashtags
+//
+
+func (s *scanner) scanCashtag(p int, prev rune, prevStart int) {
+	if p > 0 && (prevStart < s.cashtagOffset || !isUnicodeSpace(prev)) {
+		return
+	}
+
+	symbolStart := p + 1
+	symbolEnd := s.letterRun(symbolStart, 6)
+	if symbolEnd == symbolStart {
+		return
+	}
+
+	// A suffix such as ".A" in "$BRK.A" is only kept if it is properly
+	// terminated, otherwise the bare symbol is, which is always followed by
+	// the suffix's '.' or '_'
+	end := symbolEnd
+	if end < len(s.text) && (s.text[end] == '.' || s.text[end] == '_') {
+		if suffixEnd := s.letterRun(end+1, 2); suffixEnd > end+1 {
+			end = suffixEnd
+		}
+	}
+	s.cashtagOffset = end
+	if end > symbolEnd && s.isCashtagEnd(end) {
+		symbolEnd = end
+	} else if end == symbolEnd && !s.isCashtagEnd(symbolEnd) {
+		return
+	}
+
+	s.cashtags = append(s.cashtags, &ByteEntity{
+		Text:         s.text[p:symbolEnd],
+		cashtag:      s.text[symbolStart:symbolEnd],
+		cashtagIsSet: true,
+		ByteRange:    Range{Start: p, Stop: symbolEnd},
+		Type:         Cashtag,
+	})
+}
+
+// letterRun returns the end of the run of at most n letters starting at i
+func (s *scanner) letterRun(i, n int) int {
+	for ; n > 0 && i < len(s.text); n-- {
+		r, w := s.decode(i)
+		if !isLetter(r) {
+			break
+		}
+		i += w
+	}
+	return i
+}
+
+func (s *scanner) isCashtagEnd(i int) bool {
+	if i == len(s.text) {
+		return true
+	}
+	r, _ := s.decode(i)
+	return isUnicodeSpace(r) || punctuationChars.has(r)
+}
+
+//
+// URLs
+//
+
+// scanURL looks for URLs starting at the character at p, of width w. The
+// search for URLs may resume in the middle of a character: the bytes of the
+// character from that point are then tried one at a time
+func (s *scanner) scanURL(p, w int) {
+	for b := p; b < p+w; {
+		if b < s.urlOffset {
+			b = s.urlOffset
+			continue
+		}
+
+		// The candidate either starts after its preceding character, or
+		// directly at the point where the search resumed
+		r, rw := s.decode(b)
+		var m urlMatch
+		ok := false
+		if isURLPrecedingChar(r) {
+			m, ok = s.matchURL(b, b+rw)
+		}
+		if !ok && b == s.urlOffset {
+			m, ok = s.matchURL(b, b)
+		}
+		if ok {
+			s.addURL(m)
+		}
+		b += rw
+	}
+}
+
+// addURL turns a URL candidate into a URL entity, and sets the offset from
+// which the search for URLs resumes
+func (s *scanner) addURL(m urlMatch) {
+	text := s.text
+	s.urlOffset = m.end - 1
+
+	if m.protocol.Start >= 0 {
+		// t.co URLs are restricted to alphanumeric paths
+		end := m.url.Stop
+		if n := tcoLength(text[m.url.Start:end]); n > 0 {
+			end = m.url.Start + n
+		}
+
+		// Keep the components of the candidate, clipped to the end of the
+		// (possibly shortened) URL
+		clip := func(r Range) Range {
+			if r.Start < 0 || r.Start >= end {
+				return noRange
+			}
+			if r.Stop > end {
+				r.Stop = end
+			}
+			return r
+		}
+		s.urls = append(s.urls, &ByteEntity{
+			Text:      text[m.url.Start:end],
+			ByteRange: Range{Start: m.url.Start, Stop: end},
+			Type:      URL,
+			urlParts: newURLParts(text,
+				clip(m.protocol), clip(m.domain), clip(m.port), clip(m.path), clip(m.query),
+			),
+			urlPartsIsSet: true,
+		})
+		return
+	}
+
+	// URLs without a protocol must not follow some punctuation, and only
+	// their ASCII domain is extracted, e.g. "twitter.com" from
+	// "한국twitter.com"
+	if !s.x.extractURLsWithoutProtocol ||
+		m.before.Stop > m.before.Start && strings.IndexByte("-_./", text[m.before.Stop-1]) >= 0 {
+		return
+	}
+	start, end, ok := s.matchASCIIDomain(m.domain.Start, m.domain.Stop)
+	if !ok {
+		return
+	}
+	entity := &ByteEntity{
+		Text:          text[start:end],
+		ByteRange:     Range{Start: start, Stop: end},
+		Type:          URL,
+		urlParts:      newURLParts(text, noRange, Range{start, end}, noRange, noRange, noRange),
+		urlPartsIsSet: true,
+	}
+	s.urlOffset = end - 1
+
+	// Short domains under a country TLD, such as "foo.jp", are only
+	// extracted when followed by a path, except for the common "co" and "tv"
+	// domains
+	short := isShortDomain(entity.Text, s.x.countryTLDs)
+	if !short {
+		s.urls = append(s.urls, entity)
+	}
+	if m.path.Start >= 0 && m.path.Start == end {
+		if short {
+			s.urls = append(s.urls, entity)
+		}
+		entity.Text += text[m.path.Start:m.path.Stop]
+		entity.urlParts = newURLParts(
+			entity.Text,
+			noRange,
+			Range{0, len(entity.urlParts.Host)},
+			noRange,
+			Range{len(entity.urlParts.Host), len(entity.Text)},
+			noRange,
+		)
+		entity.ByteRange.Stop = m.path.Stop
+		s.urlOffset = m.path.Stop - 1
+	} else if isShortDomain(entity.Text, specialShortDomainTLDs) {
+		s.urls = append(s.urls, entity)
+	}
+}
+
+// The country TLDs whose short domains are extracted without a path
+var specialShortDomainTLDs = map[string]bool{"co": true, "tv": true}
+
+// isShortDomain reports whether domain is a single label under one of the
+// given TLDs
+func isShortDomain(domain string, tlds map[string]bool) bool {
+	i := strings.IndexByte(domain, '.')
+	if i < 0 || !tlds[domain[i+1:]] {
+		return false
+	}
+	label := domain[:i]
+	if label == "" {
+		return false
+	}
+	first, _ := utf8.DecodeRuneInString(label)
+	last, _ := utf8.DecodeLastRuneInString(label)
+	if !isURLChar(first) || !isURLChar(last) {
+		return false
+	}
+	for _, r := range label {
+		if !isURLChar(r) && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// matchURL matches a URL candidate starting at q, after the preceding
+// character starting at before
+func (s *scanner) matchURL(before, q int) (urlMatch, bool) {
+	m := urlMatch{
+		before:   Range{before, q},
+		protocol: noRange,
+		port:     noRange,
+		path:     noRange,
+		query:    noRange,
+	}
+
+	var domainEnd int
+	ok := false
+	if n := protocolLength(s.text[q:]); n > 0 {
+		if domainEnd, ok = s.matchDomain(q + n); ok {
+			m.protocol = Range{q, q + n}
+			m.domain = Range{q + n, domainEnd}
+		}
+	}
+	if !ok {
+		if domainEnd, ok = s.matchDomain(q); !ok {
+			return m, false
+		}
+		m.domain = Range{q, domainEnd}
+	}
+
+	pos := domainEnd
+	if pos < len(s.text) && s.text[pos] == ':' {
+		end := pos + 1
+		for end < len(s.text) && s.text[end] >= '0' && s.text[end] <= '9' {
+			end++
+		}
+		if end > pos+1 && s.isURLEnd(end) {
+			m.port = Range{pos + 1, end}
+			pos = end
+		}
+	}
+	if pos < len(s.text) && s.text[pos] == '/' {
+		if end := s.matchPath(pos); end >= 0 {
+			m.path = Range{pos, end}
+			pos = end
+		}
+	}
+	if pos < len(s.text) && s.text[pos] == '?' {
+		if end := s.matchQuery(pos); end >= 0 {
+			m.query = Range{pos, end}
+			pos = end
+		}
+	}
+
+	m.url = Range{q, pos}
+	m.end = pos
+	if pos < len(s.text) {
+		_, w := s.decode(pos)
+		m.end += w
+	}
+	return m, true
+}
+
+// isURLEnd reports whether a URL may end at i
+func (s *scanner) isURLEnd(i int) bool {
+	if i >= len(s.text) {
+		return true
+	}
+	r, _ := s.decode(i)
+	return !isAlnum(r) && r != '@'
+}
+
+// protocolLength returns the length of the "http://" or "https://" prefix of
+// text, ignoring case, or 0 if there is none
+func protocolLength(text string) int {
+	if text == "" || text[0] != 'h' && text[0] != 'H' {
+		return 0
+	}
+	n := foldPrefix(text, "http")
+	if n < 0 {
+		return 0
+	}
+	if m := foldPrefix(text[n:], "s"); m > 0 && strings.HasPrefix(text[n+m:], "://") {
+		return n + m + 3
+	}
+	if strings.HasPrefix(text[n:], "://") {
+		return n + 3
+	}
+	return 0
+}
+
+// tcoLength returns the length of the "http://t.co/" URL and alphanumeric
+// path which url starts with, ignoring case, or 0 if there is none
+func tcoLength(url string) int {
+	n := protocolLength(url)
+	if n == 0 {
+		return 0
+	}
+	m := foldPrefix(url[n:], "t.co/")
+	if m < 0 {
+		return 0
+	}
+	end := n + m
+	for end < len(url) {
+		r, w := utf8.DecodeRuneInString(url[end:])
+		if !isAlnum(r) {
+			break
+		}
+		end += w
+	}
+	if end == n+m {
+		return 0
+	}
+	return end
+}
+
+// foldPrefix returns the length of the prefix of text equal to lit under
+// simple case folding, or -1 if there is none
+func foldPrefix(text, lit string) int {
+	n := 0
+	for _, c := range lit {
+		if n >= len(text) {
+			return -1
+		}
+		r, w := utf8.DecodeRuneInString(text[n:])
+		if r != c && foldRune(r) != foldRune(c) {
+			return -1
+		}
+		n += w
+	}
+	return n
+}
+
+// matchDomain matches a domain starting at d, and returns its end. The
+// domain is made of labels separated by dots, where only labels before the
+// second-level domain may contain underscores, and it ends in a TLD or a
+// punycode label. Domains with the most labels are preferred
+func (s *scanner) matchDomain(d int) (int, bool) {
+	labels := s.labels[:0]
+	for i := d; ; {
+		end, valid, underscore := s.labelRunAt(i)
+		if !valid || end >= len(s.text) || s.text[end] != '.' {
+			break
+		}
+		labels = append(labels, domainLabel{end, underscore})
+		i = end + 1
+	}
+	s.labels = labels
+
+	for k := len(labels) - 1; k >= 0; k-- {
+		if labels[k].underscore {
+			continue
+		}
+		tld := labels[k].end + 1
+		if end := s.x.tlds.match(s.text, tld, len(s.text), true, s.isURLEnd); end >= 0 {
+			return end, true
+		}
+		if end := s.punycodeEnd(tld, len(s.text), true); end >= 0 && s.isURLEnd(end) {
+			return end, true
+		}
+	}
+	return 0, false
+}
+
+// labelRunAt returns the end of the run of label characters starting at i,
+// whether it forms a valid label, and whether it contains an underscore
+func (s *scanner) labelRunAt(i int) (end int, valid, underscore bool) {
+	if i < s.run.start || i >= s.run.end {
+		s.run = labelRun{start: i, end: i, underscore: -1}
+		for s.run.end < len(s.text) {
+			r, w := s.decode(s.run.end)
+			if !isURLChar(r) && r != '_' && r != '-' {
+				break
+			}
+			if r == '_' {
+				s.run.underscore = s.run.end
+			}
+			s.run.validEnd = isURLChar(r)
+			s.run.end += w
+		}
+		if i == s.run.end {
+			return i, false, false
+		}
+	}
+
+	first, _ := s.decode(i)
+	return s.run.end, isURLChar(first) && s.run.validEnd, s.run.underscore >= i
+}
+
+// punycodeEnd returns the end of the punycode label starting at i and ending
+// at or before limit, or -1 if there is none
+func (s *scanner) punycodeEnd(i, limit int, fold bool) int {
+	var n int
+	if fold {
+		n = foldPrefix(s.text[i:limit], "xn--")
+	} else if strings.HasPrefix(s.text[i:limit], "xn--") {
+		n = 4
+	} else {
+		n = -1
+	}
+	if n < 0 {
+		return -1
+	}
+
+	start := i + n
+	end := start
+	for end < limit {
+		r, w := utf8.DecodeRuneInString(s.text[end:limit])
+		if fold && !isAlnum(r) || !fold && !(r >= '0' && r <= '9' || r >= 'a' && r <= 'z') {
+			break
+		}
+		end += w
+	}
+	if end == start {
+		return -1
+	}
+	return end
+}
+
+// matchPath matches the path starting with the slash at i, and returns its
+// end, or -1 if the slash cannot start a path. The path is the longest run of
+// path characters and balanced parentheses ending in a path ending
+// character or parentheses, after which the URL may end
+func (s *scanner) matchPath(i int) int {
+	best := -1
+	end := i + 1
+	if s.isPathEnd(end) {
+		best = end
+	}
+	for end < len(s.text) {
+		r, w := s.decode(end)
+		if isURLPathChar(r) {
+			end += w
+			if isURLPathEndingChar(r) && s.isPathEnd(end) {
+				best = end
+			}
+			continue
+		}
+		if r != '(' {
+			break
+		}
+
+		// Balanced parentheses around at least one path character
+		closing := end + 1
+		for closing < len(s.text) {
+			r, w := s.decode(closing)
+			if !isURLPathChar(r) {
+				break
+			}
+			closing += w
+		}
+		if closing == end+1 || closing == len(s.text) || s.text[closing] != ')' {
+			break
+		}
+		end = closing + 1
+		if s.isPathEnd(end) {
+			best = end
+		}
+	}
+	return best
+}
+
+func (s *scanner) isPathEnd(i int) bool {
+	return i < len(s.text) && s.text[i] == '?' || s.isURLEnd(i)
+}
+
+// matchQuery matches the query string starting with the question mark at i,
+// and returns its end, or -1 if there is none
+func (s *scanner) matchQuery(i int) int {
+	best := -1
+	for end := i + 1; end < len(s.text); {
+		r, w := s.decode(end)
+		if !isURLQueryChar(r) {
+			break
+		}
+		end += w
+		if isURLQueryEndingChar(r) && s.isURLEnd(end) {
+			best = end
+		}
+	}
+	return best
+}
+
+// matchASCIIDomain finds the first domain within text[start:end] whose
+// labels are ASCII, Latin accented letters, underscores and hyphens and
+// start with an ASCII letter or digit. The TLD is matched case-sensitively,
+// and need not end the domain
+func (s *scanner) matchASCIIDomain(start, end int) (int, int, bool) {
+	for i := start; i < end; {
+		r, w := utf8.DecodeRuneInString(s.text[i:end])
+		if !isASCIIAlnum(r) {
+			i += w
+			continue
+		}
+
+		labels := s.labels[:0]
+		firstEnd := -1
+		for j := i; j < end; {
+			if r, _ := utf8.DecodeRuneInString(s.text[j:end]); !isASCIIAlnum(r) {
+				break
+			}
+			k := j
+			for k < end {
+				r, w := utf8.DecodeRuneInString(s.text[k:end])
+				if !isASCIIAlnum(r) && r != '_' && r != '-' && !isLatinAccent(r) {
+					break
+				}
+				k += w
+			}
+			if firstEnd < 0 {
+				firstEnd = k
+			}
+			if k >= end || s.text[k] != '.' {
+				break
+			}
+			labels = append(labels, domainLabel{end: k})
+			j = k + 1
+		}
+		s.labels = labels
+
+		for k := len(labels) - 1; k >= 0; k-- {
+			tld := labels[k].end + 1
+			if e := s.x.tlds.match(s.text, tld, end, false, acceptAnyEnd); e >= 0 {
+				return i, e, true
+			}
+			if e := s.punycodeEnd(tld, end, false); e >= 0 {
+				return i, e, true
+			}
+		}
+
+		// Starting later within the first label gives the same labels
+		i = firstEnd
+	}
+	return 0, 0, false
+}
+
+func acceptAnyEnd(int) bool {
+	return true
+}
+
+func firstRune(s string) rune {
+	r, _ := utf8.DecodeRuneInString(s)
+	return r
+}
@@ -0,0 +1,123 @@
+package extract
+
+import (
+	"io/ioutil"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	goyaml "gopkg.in/yaml.v1"
+)
+
+// Fragments which are joined at random to exercise the corner cases of the
+// extraction rules: protocols, domains next to non-ASCII text, balanced
+// parentheses, trailing punctuation, fullwidth signs, case variants of ASCII
+// letters and invalid UTF-8
+var scannerFragments = []string{
+	"http://", "https://", "HTTPS://", "www.", "t.co/", "T.CO/abc",
+	"example", ".com", ".COM", ".co", ".jp", ".tv", ".community", ".xn--p1ai",
+	"xn--", "foo-bar", "foo_bar", "_", "-", ".", "..", ":", ":8080", "/",
+	"/path", "/p(a)th", "(", ")", "?q=1", "&x=y", "#", "＃", "#tag", "@", "＠",
+	"@user", "/list", "/list-name_2", "/abcdefghijklmnopqrstuvwxy", "$", "$AB", ".A", "$ab_c", "RT", "RT:", " ", "  ",
+	"\u3000", "\n", "!", ",", "'", "é", "É", "한국", "日本", "\u017f", "\u212a",
+	"\u202a", "\u200d", "\xff", "\xe3\x81", "123", "a", "Z",
+}
+
+func scannerTestTexts(t *testing.T) []string {
+	var texts []string
+	for _, file := range []string{extractYmlPath, tldYmlPath} {
+		contents, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatalf("Error reading %s: %v", file, err)
+		}
+		var conformance = &Conformance{}
+		if err := goyaml.Unmarshal(contents, &conformance); err != nil {
+			t.Fatalf("Error parsing %s: %v", file, err)
+		}
+		for _, tests := range conformance.Tests {
+			for _, test := range tests {
+				texts = append(texts, test.Text)
+			}
+		}
+	}
+
+	random := rand.New(rand.NewSource(1))
+	for i := 0; i < 10000; i++ {
+		var b strings.Builder
+		for n := 1 + random.Intn(12); n > 0; n-- {
+			b.WriteString(scannerFragments[random.Intn(len(scannerFragments))])
+		}
+		texts = append(texts, b.String())
+	}
+	return texts
+}
+
+func TestScannerMatchesReference(t *testing.T) {
+	texts := scannerTestTexts(t)
+	options := map[string]func(o *ExtractorOptions){
+		"default": func(o *ExtractorOptions) {},
+		"custom TLDs": func(o *ExtractorOptions) {
+			o.GenericTLDs = []string{"com", "community", "example"}
+			o.CountryTLDs = []string{"co", "jp"}
+		},
+		"TLD registry": func(o *ExtractorOptions) {
+			o.TLDs, _ = NewTLDRegistry([]string{"com", "community", "みんな"}, []string{"jp", "ελ"})
+		},
+		"no URLs without protocol": func(o *ExtractorOptions) {
+			o.ExtractURLsWithoutProtocol = false
+		},
+		"no username separators": func(o *ExtractorOptions) {
+			o.UsernameSeparators = ""
+		},
+		"priority overlap policy": func(o *ExtractorOptions) {
+			o.OverlapPolicy = KeepPriority
+			o.EntityPriority = []EntityType{Hashtag, Mention}
+		},
+		"filtered types": func(o *ExtractorOptions) {
+			o.EntityTypes = []EntityType{Hashtag, Cashtag}
+		},
+	}
+
+	for name, modify := range options {
+		o := DefaultExtractorOptions()
+		modify(&o)
+		x, err := NewExtractor(o)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		reference, err := newReferenceExtractor(o)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		failures := 0
+		for _, text := range texts {
+			for _, f := range []struct {
+				name     string
+				actual   func(string) []*ByteEntity
+				expected func(string) []*ByteEntity
+			}{
+				{"Entities", x.Entities, reference.Entities},
+				{"URLs", x.URLs, reference.URLs},
+				{"Hashtags", x.Hashtags, reference.Hashtags},
+				{"Mentions", x.Mentions, reference.Mentions},
+				{"MentionsOrLists", x.MentionsOrLists, reference.MentionsOrLists},
+				{"Cashtags", x.Cashtags, reference.Cashtags},
+			} {
+				actual, expected := f.actual(text), f.expected(text)
+				if len(actual) == 0 && len(expected) == 0 {
+					continue
+				}
+				if !reflect.DeepEqual(actual, expected) {
+					t.Errorf("%s: %s(%q)\nexpected: %v\n     got: %v",
+						name, f.name, text, expected, actual)
+					failures++
+				}
+			}
+			if failures >= 10 {
+				t.Fatalf("%s: too many failures", name)
+			}
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package extract
+
+import (
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// ScreenNameKey returns the canonical key of a screen name, using the default
+// username separators. See Extractor.ScreenNameKey
+func ScreenNameKey(name string) string {
+	return defaultExtractor.ScreenNameKey(name)
+}
+
+// ScreenNameKey returns the canonical key of a screen name, with or without
+// the leading at sign. Screen names which look alike have the same key, so
+// the key can be used to look up accounts regardless of case and width, and
+// to reject a new screen name which impersonates an existing one. The key is
+// built by:
+//   - applying NFKC and case folding, so that "＠ＪＡＣＫ" becomes "@jack"
+//   - removing the Extractor's username separators, so that "j_ack" and
+//     "jack" have the same key
+//   - replacing confusable characters by their prototype (See: Skeleton), so
+//     that "examp1e" and "example" have the same key
+//
+// Keys are in NFC, and are only meant to be compared with each other, not
+// displayed
+func (x *Extractor) ScreenNameKey(name string) string {
+	name = norm.NFKC.String(name)
+	name = strings.TrimPrefix(name, "@")
+
+	// Lowercasing the uppercase form approximates simple case folding, e.g.
+	// for the final sigma and the long s
+	name = norm.NFKC.String(strings.ToLower(strings.ToUpper(name)))
+
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(x.usernameSeparators, r) {
+			return -1
+		}
+		return r
+	}, name)
+
+	// Prototypes may be uppercase, e.g. "0" becomes "O". Skeletons are
+	// decomposed, while keys are stored in NFC
+	return norm.NFC.String(strings.ToLower(Skeleton(name)))
+}
@@ -0,0 +1,247 @@
+package extract
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// RestrictionLevel is a UTS #39 restriction level
+// (See: http://www.unicode.org/reports/tr39/#Restriction_Level_Detection),
+// describing how the scripts of a string are combined. Levels are ordered
+// from the most to the least restrictive
+type RestrictionLevel int
+
+// RestrictionLevels
+const (
+	// Only ASCII characters
+	ASCIIOnly RestrictionLevel = iota
+
+	// Characters of a single script, plus Common and Inherited characters
+	SingleScript
+
+	// Latin combined with Han, Hiragana and Katakana; with Han and Bopomofo;
+	// or with Han and Hangul
+	HighlyRestrictive
+
+	// Latin combined with one other script, except Cyrillic and Greek
+	ModeratelyRestrictive
+
+	// Any combination of scripts
+	MinimallyRestrictive
+
+	// Characters which are not letters, marks, digits or hyphens
+	Unrestricted
+)
+
+// String implements the Stringer interface
+func (l RestrictionLevel) String() string {
+	switch l {
+	case ASCIIOnly:
+		return "ASCII-Only"
+	case SingleScript:
+		return "Single Script"
+	case HighlyRestrictive:
+		return "Highly Restrictive"
+	case ModeratelyRestrictive:
+		return "Moderately Restrictive"
+	case MinimallyRestrictive:
+		return "Minimally Restrictive"
+	case Unrestricted:
+		return "Unrestricted"
+	}
+	return "Unknown"
+}
+
+// The script combinations allowed by the Highly Restrictive level
+var highlyRestrictiveScripts = []map[string]bool{
+	{"Latin": true, "Han": true, "Hiragana": true, "Katakana": true},
+	{"Latin": true, "Han": true, "Bopomofo": true},
+	{"Latin": true, "Han": true, "Hangul": true},
+}
+
+// RestrictionLevelOf returns the restriction level of s. Characters are
+// assigned their Script property; the Script_Extensions property is not
+// available, so a few characters shared by several scripts count as only one
+// of them
+func RestrictionLevelOf(s string) RestrictionLevel {
+	level := scriptLevel(scriptsOf(s))
+	for _, r := range s {
+		if !(unicode.IsLetter(r) || unicode.IsMark(r) || unicode.IsDigit(r) || r == '-' || r == '_') {
+			return Unrestricted
+		}
+	}
+	if level == SingleScript && isASCII(s) {
+		return ASCIIOnly
+	}
+	return level
+}
+
+// scriptsOf returns the set of scripts used by s, ignoring Common and
+// Inherited characters which are used with any script
+func scriptsOf(s string) map[string]bool {
+	scripts := map[string]bool{}
+	for _, r := range s {
+		if script := scriptOf(r); script != "Common" && script != "Inherited" {
+			scripts[script] = true
+		}
+	}
+	return scripts
+}
+
+func scriptOf(r rune) string {
+	switch {
+	case r < utf8.RuneSelf:
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' {
+			return "Latin"
+		}
+		return "Common"
+	case unicode.Is(unicode.Common, r):
+		return "Common"
+	case unicode.Is(unicode.Inherited, r):
+		return "Inherited"
+	}
+	for name, table := range unicode.Scripts {
+		if unicode.Is(table, r) {
+			return name
+		}
+	}
+	return "Unknown"
+}
+
+// scriptLevel returns the restriction level allowing the given scripts,
+// which is at most MinimallyRestrictive
+func scriptLevel(scripts map[string]bool) RestrictionLevel {
+	if len(scripts) <= 1 {
+		return SingleScript
+	}
+	for _, allowed := range highlyRestrictiveScripts {
+		covered := true
+		for script := range scripts {
+			covered = covered && allowed[script]
+		}
+		if covered {
+			return HighlyRestrictive
+		}
+	}
+	if len(scripts) == 2 && scripts["Latin"] && !scripts["Cyrillic"] && !scripts["Greek"] {
+		return ModeratelyRestrictive
+	}
+	return MinimallyRestrictive
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// HostSpoofing describes how a URL host may imitate another one. Each label
+// of the host is checked separately, as browsers do for internationalized
+// domain names
+type HostSpoofing struct {
+	// The least restrictive level of any label
+	Level RestrictionLevel `json:"level"`
+
+	// A label mixes scripts beyond the Moderately Restrictive level, such as
+	// Latin with Cyrillic
+	MixedScript bool `json:"mixed_script,omitempty"`
+
+	// A label is written in a single script other than Latin, but only uses
+	// characters which look like Latin letters and digits, e.g. Cyrillic
+	// "раура1"
+	WholeScriptConfusable bool `json:"whole_script_confusable,omitempty"`
+
+	// The protected domain that the host looks like without being that
+	// domain or one of its subdomains, or empty
+	Lookalike string `json:"lookalike,omitempty"`
+}
+
+// Suspicious reports whether any kind of spoofing was found
+func (s HostSpoofing) Suspicious() bool {
+	return s.MixedScript || s.WholeScriptConfusable || s.Lookalike != ""
+}
+
+// CheckHostSpoofing analyses a host for mixed-script labels, whole-script
+// confusable labels and lookalikes of the protected domains. The host may be
+// in Unicode or punycode form. Returns an error if the host or one of the
+// protected domains is not a valid internationalized domain name
+func CheckHostSpoofing(host string, protectedDomains []string) (HostSpoofing, error) {
+	protected, err := newProtectedDomains(protectedDomains)
+	if err != nil {
+		return HostSpoofing{}, err
+	}
+	ascii, err := HostToASCII(host)
+	if err != nil {
+		return HostSpoofing{}, err
+	}
+	unicodeHost, err := HostToUnicode(ascii)
+	if err != nil {
+		return HostSpoofing{}, err
+	}
+	return protected.check(unicodeHost), nil
+}
+
+// protectedDomains maps the skeletons of protected domains to the domains,
+// in Unicode form
+type protectedDomains map[string]string
+
+func newProtectedDomains(domains []string) (protectedDomains, error) {
+	protected := make(protectedDomains, len(domains))
+	for _, domain := range domains {
+		ascii, err := HostToASCII(domain)
+		if err != nil {
+			return nil, err
+		}
+		unicodeDomain, err := HostToUnicode(ascii)
+		if err != nil {
+			return nil, err
+		}
+		protected[hostSkeleton(unicodeDomain)] = unicodeDomain
+	}
+	return protected, nil
+}
+
+// hostSkeleton returns the skeleton of a host, lowercased since prototypes
+// may be uppercase (e.g. "0" becomes "O") while hosts are lowercase
+func hostSkeleton(host string) string {
+	return strings.ToLower(Skeleton(host))
+}
+
+// check analyses a host converted by HostToUnicode
+func (p protectedDomains) check(host string) HostSpoofing {
+	var result HostSpoofing
+	for _, label := range strings.Split(host, ".") {
+		if level := RestrictionLevelOf(label); level > result.Level {
+			result.Level = level
+		}
+		scripts := scriptsOf(label)
+		if scriptLevel(scripts) > ModeratelyRestrictive {
+			result.MixedScript = true
+		}
+		if len(scripts) == 1 && !scripts["Latin"] && isASCII(Skeleton(label)) {
+			result.WholeScriptConfusable = true
+		}
+	}
+
+	// Compare the host and each of its parent domains with the protected
+	// domains
+	skeleton := hostSkeleton(host)
+	for {
+		if domain, ok := p[skeleton]; ok {
+			if host != domain && !strings.HasSuffix(host, "."+domain) {
+				result.Lookalike = domain
+			}
+			break
+		}
+		i := strings.IndexByte(skeleton, '.')
+		if i < 0 {
+			break
+		}
+		skeleton = skeleton[i+1:]
+	}
+	return result
+}
@@ -0,0 +1,97 @@
+package extract
+
+import (
+	"testing"
+)
+
+func TestSkeleton(t *testing.T) {
+	tests := []struct {
+		description string
+		a           string
+		b           string
+		confusable  bool
+	}{
+		{"Identical", "paypal", "paypal", true},
+		{"Cyrillic a", "pаypal", "paypal", true},
+		{"Whole-script Cyrillic", "раураl", "paypal", true},
+		{"Digit one and letter l", "paypa1", "paypal", true},
+		{"Capital I and letter l", "PaypaI", "Paypal", true},
+		{"Fullwidth letters", "ｐａｙ", "pay", true},
+		{"Greek omicron", "gοogle", "google", true},
+		{"Decomposed accent", "cafe\u0301", "caf\u00e9", true},
+		{"Digit zero and letter o", "g00gle", "google", false},
+		{"Different letters", "paypal", "paypol", false},
+	}
+
+	for _, test := range tests {
+		if actual := Skeleton(test.a) == Skeleton(test.b); actual != test.confusable {
+			t.Errorf(
+				"Skeleton returned incorrect value for test [%s]. Expected:%v Got:%v",
+				test.description,
+				test.confusable,
+				actual,
+			)
+		}
+	}
+}
+
+func TestRestrictionLevelOf(t *testing.T) {
+	tests := []struct {
+		description string
+		text        string
+		expected    RestrictionLevel
+	}{
+		{"ASCII", "abc-123", ASCIIOnly},
+		{"Latin with accents", "münchen", SingleScript},
+		{"Cyrillic", "яндекс", SingleScript},
+		{"Han", "日本語", SingleScript},
+		{"Katakana and Han", "ドメイン名例", HighlyRestrictive},
+		{"Hangul and Latin", "한국어abc", HighlyRestrictive},
+		{"Latin and Arabic", "abcاب", ModeratelyRestrictive},
+		{"Latin and Cyrillic", "pаypal", MinimallyRestrictive},
+		{"Latin and Greek", "abcαβ", MinimallyRestrictive},
+		{"Symbol", "ab☃", Unrestricted},
+	}
+
+	for _, test := range tests {
+		if actual := RestrictionLevelOf(test.text); actual != test.expected {
+			t.Errorf(
+				"RestrictionLevelOf returned incorrect value for test [%s]. Expected:%v Got:%v",
+				test.description,
+				test.expected,
+				actual,
+			)
+		}
+	}
+}
+
+func TestCheckHostSpoofingLookalike(t *testing.T) {
+	protected := []string{"google.com", "paypal.com"}
+	tests := []struct {
+		host     string
+		expected string
+	}{
+		{"g00gle.com", "google.com"},
+		{"G00GLE.com", "google.com"},
+		{"login.g00gle.com", "google.com"},
+		{"paypa1.com", "paypal.com"},
+		{"google.com", ""},
+		{"g00gle.org", ""},
+	}
+
+	for _, test := range tests {
+		spoofing, err := CheckHostSpoofing(test.host, protected)
+		if err != nil {
+			t.Errorf("CheckHostSpoofing failed for %s: %v", test.host, err)
+			continue
+		}
+		if spoofing.Lookalike != test.expected {
+			t.Errorf(
+				"CheckHostSpoofing returned incorrect lookalike for %s. Expected:%q Got:%q",
+				test.host,
+				test.expected,
+				spoofing.Lookalike,
+			)
+		}
+	}
+}
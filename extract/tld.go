@@ -0,0 +1,107 @@
+package extract
+
+import (
+	"sort"
+	"unicode"
+	"unicode/utf8"
+)
+
+// tldSet looks up the TLDs a domain may end in. TLDs are ranked, and the
+// first TLD in rank order which matches at a position wins even if a later
+// one is longer, as with the alternation of a regular expression
+type tldSet struct {
+	exact  map[string]int // TLD -> rank
+	folded map[string]int // case-folded TLD -> rank
+
+	// The length of the longest TLD, in runes
+	maxRunes int
+}
+
+// newTLDSet returns a set of the TLDs of the given lists, ranked in order
+func newTLDSet(lists ...[]string) *tldSet {
+	s := &tldSet{exact: map[string]int{}, folded: map[string]int{}}
+	rank := 0
+	for _, list := range lists {
+		for _, tld := range list {
+			if _, ok := s.exact[tld]; !ok {
+				s.exact[tld] = rank
+			}
+			if key := foldString(tld); !s.has(key) {
+				s.folded[key] = rank
+			}
+			if n := utf8.RuneCountInString(tld); n > s.maxRunes {
+				s.maxRunes = n
+			}
+			rank++
+		}
+	}
+	return s
+}
+
+func (s *tldSet) has(folded string) bool {
+	_, ok := s.folded[folded]
+	return ok
+}
+
+// match returns the end of the first TLD, in rank order, which starts at
+// text[start] and ends at or before limit, and for which accept returns true.
+// Returns -1 if there is none. fold selects case-insensitive matching
+func (s *tldSet) match(text string, start, limit int, fold bool, accept func(end int) bool) int {
+	var (
+		buf      [64]byte
+		key      = buf[:0]
+		r        [utf8.UTFMax]byte
+		bestEnd  = -1
+		bestRank = -1
+	)
+	table := s.exact
+	if fold {
+		table = s.folded
+	}
+	for i, n := start, 0; i < limit && n < s.maxRunes; n++ {
+		c, w := utf8.DecodeRuneInString(text[i:limit])
+		if fold {
+			c = foldRune(c)
+		}
+		key = append(key, r[:utf8.EncodeRune(r[:], c)]...)
+		i += w
+		if rank, ok := table[string(key)]; ok && (bestRank < 0 || rank < bestRank) && accept(i) {
+			bestEnd, bestRank = i, rank
+		}
+	}
+	return bestEnd
+}
+
+// sortTLDs returns a list of TLDs in rank order: longer TLDs first so that
+// a TLD which is a prefix of another never shadows it
+func sortTLDs(tlds []string) []string {
+	sorted := make([]string, len(tlds))
+	copy(sorted, tlds)
+	sort.Slice(sorted, func(i, j int) bool {
+		if len(sorted[i]) != len(sorted[j]) {
+			return len(sorted[i]) > len(sorted[j])
+		}
+		return sorted[i] < sorted[j]
+	})
+	return sorted
+}
+
+// foldRune maps r to a canonical member of its case folding orbit, so that
+// runes which are equal under simple case folding map to the same rune
+func foldRune(r rune) rune {
+	min := r
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		if f < min {
+			min = f
+		}
+	}
+	return min
+}
+
+func foldString(s string) string {
+	b := make([]rune, 0, len(s))
+	for _, r := range s {
+		b = append(b, foldRune(r))
+	}
+	return string(b)
+}
@@ -0,0 +1,135 @@
+package extract
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TLDRegistry is a set of top-level domains which URLs may end in, split into
+// generic and country code TLDs. TLDs are stored in their Unicode form, e.g.
+// "рф" rather than "xn--p1ai": punycode TLDs are recognized whatever the
+// registry. A TLDRegistry is immutable and safe for concurrent use
+type TLDRegistry struct {
+	generic []string
+	country []string
+}
+
+// The Internationalized country code TLDs. The IANA list does not tell
+// country code TLDs from generic ones, and only the ASCII ones can be told
+// apart by their length
+var idnCountryTLDs = []string{
+	"ελ", "бел", "мкд", "мон", "рф", "срб", "укр", "қаз", "հայ", "الاردن", "الجزائر",
+	"السعودية", "المغرب", "امارات", "ایران", "بھارت", "تونس", "سودان", "سورية", "عراق", "عمان",
+	"فلسطين", "قطر", "مصر", "مليسيا", "پاکستان", "भारत", "বাংলা", "ভারত", "ਭਾਰਤ", "ભારત",
+	"இந்தியா", "இலங்கை", "சிங்கப்பூர்", "భారత్", "ලංකා", "ไทย", "გე", "中国", "中國", "台湾",
+	"台灣", "新加坡", "澳門", "香港", "한국",
+}
+
+var defaultTLDRegistry = &TLDRegistry{generic: genericTLDs, country: countryTLDs}
+
+// DefaultTLDRegistry returns the built-in registry, generated from the IANA
+// list of TLDs by the tldgen command
+func DefaultTLDRegistry() *TLDRegistry {
+	return defaultTLDRegistry
+}
+
+// NewTLDRegistry returns a registry of the given generic and country code
+// TLDs. A TLD must not be empty or contain dots or spaces. A TLD listed in
+// both lists is a country code TLD
+func NewTLDRegistry(generic, country []string) (*TLDRegistry, error) {
+	r := &TLDRegistry{}
+	isCountry := map[string]bool{}
+	for _, tld := range country {
+		if err := checkTLD(tld); err != nil {
+			return nil, err
+		}
+		isCountry[tld] = true
+	}
+	isGeneric := map[string]bool{}
+	for _, tld := range generic {
+		if err := checkTLD(tld); err != nil {
+			return nil, err
+		}
+		if !isCountry[tld] {
+			isGeneric[tld] = true
+		}
+	}
+	r.generic = sortedKeys(isGeneric)
+	r.country = sortedKeys(isCountry)
+	return r, nil
+}
+
+// ParseTLDRegistry reads a registry in the format of the IANA list of TLDs
+// (See: https://data.iana.org/TLD/tlds-alpha-by-domain.txt): one TLD per
+// line, with comments starting with '#'. Punycode TLDs are converted to
+// their Unicode form. Two-letter ASCII TLDs and the known internationalized
+// country code TLDs are country code TLDs, other TLDs are generic
+func ParseTLDRegistry(r io.Reader) (*TLDRegistry, error) {
+	isCountry := map[string]bool{}
+	for _, tld := range idnCountryTLDs {
+		isCountry[tld] = true
+	}
+
+	var generic, country []string
+	s := bufio.NewScanner(r)
+	for line := 1; s.Scan(); line++ {
+		tld := strings.TrimSpace(s.Text())
+		if tld == "" || strings.HasPrefix(tld, "#") {
+			continue
+		}
+		tld = strings.ToLower(tld)
+		if strings.HasPrefix(tld, "xn--") {
+			converted, err := HostToUnicode(tld)
+			if err != nil {
+				return nil, fmt.Errorf("%v (line %d)", err, line)
+			}
+			tld = converted
+		}
+		if err := checkTLD(tld); err != nil {
+			return nil, fmt.Errorf("%v (line %d)", err, line)
+		}
+
+		if isCountry[tld] || len(tld) == 2 && isASCIIAlnum(rune(tld[0])) && isASCIIAlnum(rune(tld[1])) {
+			country = append(country, tld)
+		} else {
+			generic = append(generic, tld)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return NewTLDRegistry(generic, country)
+}
+
+// GenericTLDs returns the generic TLDs of the registry, in byte order
+func (r *TLDRegistry) GenericTLDs() []string {
+	return append([]string(nil), r.generic...)
+}
+
+// CountryTLDs returns the country code TLDs of the registry, in byte order
+func (r *TLDRegistry) CountryTLDs() []string {
+	return append([]string(nil), r.country...)
+}
+
+// checkTLD returns an error if tld cannot be a TLD
+func checkTLD(tld string) error {
+	if tld == "" || strings.ContainsRune(tld, '.') || !utf8.ValidString(tld) ||
+		strings.IndexFunc(tld, unicode.IsSpace) >= 0 {
+		return fmt.Errorf("extract: invalid TLD %q", tld)
+	}
+	return nil
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
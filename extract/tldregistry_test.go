@@ -0,0 +1,94 @@
+package extract
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseTLDRegistry(t *testing.T) {
+	list := "# Version 2019062000, Last Updated Thu Jun 20 07:07:01 2019 UTC\n" +
+		"APP\nCOM\nJP\nXN--P1AI\nXN--80ASEHDB\n\nCOM\n"
+	r, err := ParseTLDRegistry(strings.NewReader(list))
+	if err != nil {
+		t.Fatalf("ParseTLDRegistry failed: %v", err)
+	}
+
+	if expected := "[app com онлайн]"; fmt.Sprint(r.GenericTLDs()) != expected {
+		t.Errorf("Wrong generic TLDs. Expected:%s Got:%v", expected, r.GenericTLDs())
+	}
+	if expected := "[jp рф]"; fmt.Sprint(r.CountryTLDs()) != expected {
+		t.Errorf("Wrong country TLDs. Expected:%s Got:%v", expected, r.CountryTLDs())
+	}
+}
+
+func TestParseTLDRegistryInvalid(t *testing.T) {
+	tests := []struct {
+		description string
+		list        string
+		err         string
+	}{
+		{"Domain name", "COM\nCO.UK\n", `extract: invalid TLD "co.uk" (line 2)`},
+		{"Invalid punycode", "XN--A\n", `(line 1)`},
+	}
+
+	for _, test := range tests {
+		_, err := ParseTLDRegistry(strings.NewReader(test.list))
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("ParseTLDRegistry returned the wrong error for test [%s]. Expected:%s Got:%v",
+				test.description, test.err, err)
+		}
+	}
+}
+
+func TestNewTLDRegistry(t *testing.T) {
+	r, err := NewTLDRegistry([]string{"com", "io", "app"}, []string{"io", "jp"})
+	if err != nil {
+		t.Fatalf("NewTLDRegistry failed: %v", err)
+	}
+	if expected := "[app com]"; fmt.Sprint(r.GenericTLDs()) != expected {
+		t.Errorf("Wrong generic TLDs. Expected:%s Got:%v", expected, r.GenericTLDs())
+	}
+	if expected := "[io jp]"; fmt.Sprint(r.CountryTLDs()) != expected {
+		t.Errorf("Wrong country TLDs. Expected:%s Got:%v", expected, r.CountryTLDs())
+	}
+
+	if _, err := NewTLDRegistry([]string{"com", ""}, nil); err == nil {
+		t.Errorf("NewTLDRegistry accepted an empty TLD")
+	}
+}
+
+func TestDefaultTLDRegistry(t *testing.T) {
+	r := DefaultTLDRegistry()
+	for _, tld := range idnCountryTLDs {
+		if !containsString(r.CountryTLDs(), tld) {
+			t.Errorf("Internationalized country TLD %s is not a country TLD of the default registry", tld)
+		}
+	}
+	for _, tld := range r.GenericTLDs() {
+		if containsString(r.CountryTLDs(), tld) {
+			t.Errorf("TLD %s is both a generic and a country TLD", tld)
+		}
+	}
+}
+
+func ExampleParseTLDRegistry() {
+	list := "# Version 2019062000\nAPP\nDEV\nUK\n"
+	registry, err := ParseTLDRegistry(strings.NewReader(list))
+	if err != nil {
+		panic(err)
+	}
+
+	options := DefaultExtractorOptions()
+	options.TLDs = registry
+	x, err := NewExtractor(options)
+	if err != nil {
+		panic(err)
+	}
+	for _, e := range x.URLs("example.app example.com http://example.uk") {
+		fmt.Println(e.Text)
+	}
+	// Output:
+	// example.app
+	// http://example.uk
+}
@@ -4,6 +4,7 @@ go 1.12
 
 require (
 	github.com/kr/pretty v0.1.0 // indirect
+	golang.org/x/net v0.0.0-20190620200207-3b0461eec859
 	golang.org/x/text v0.3.2
 	gopkg.in/check.v1 v1.0.0-20180628173108-788fd7840127 // indirect
 	gopkg.in/yaml.v1 v1.0.0-20140924161607-9f9df34309c0
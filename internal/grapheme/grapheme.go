@@ -0,0 +1,187 @@
+// Package grapheme splits text into user-perceived characters (extended
+// grapheme clusters) following the rules of UAX #29
+// (See: http://www.unicode.org/reports/tr29).
+//
+// The Extended_Pictographic property is approximated by the emoji blocks, and
+// Prepend characters are treated as ordinary characters. Both only matter for
+// rare sequences.
+package grapheme
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type property int
+
+const (
+	other property = iota
+	cr
+	lf
+	control
+	extend
+	zwj
+	regionalIndicator
+	spacingMark
+	hangulL
+	hangulV
+	hangulT
+	hangulLV
+	hangulLVT
+	pictographic
+)
+
+func propertyOf(r rune) property {
+	switch {
+	case r == '\r':
+		return cr
+	case r == '\n':
+		return lf
+	case r == 0x200D:
+		return zwj
+	case r == 0x200C,
+		r >= 0xFE00 && r <= 0xFE0F,
+		r >= 0x1F3FB && r <= 0x1F3FF, // Emoji skin tone modifiers
+		r >= 0xE0020 && r <= 0xE007F, // Tags
+		r >= 0xE0100 && r <= 0xE01EF,
+		unicode.In(r, unicode.Mn, unicode.Me):
+		return extend
+	case r >= 0x1F1E6 && r <= 0x1F1FF:
+		return regionalIndicator
+	case unicode.Is(unicode.Mc, r):
+		return spacingMark
+	case r >= 0x1100 && r <= 0x115F, r >= 0xA960 && r <= 0xA97C:
+		return hangulL
+	case r >= 0x1160 && r <= 0x11A7, r >= 0xD7B0 && r <= 0xD7C6:
+		return hangulV
+	case r >= 0x11A8 && r <= 0x11FF, r >= 0xD7CB && r <= 0xD7FB:
+		return hangulT
+	case r >= 0xAC00 && r <= 0xD7A3:
+		if (r-0xAC00)%28 == 0 {
+			return hangulLV
+		}
+		return hangulLVT
+	case isPictographic(r):
+		return pictographic
+	case unicode.In(r, unicode.Cc, unicode.Zl, unicode.Zp),
+		unicode.Is(unicode.Cf, r) && r != 0x200C && r != 0x200D:
+		return control
+	}
+	return other
+}
+
+func isPictographic(r rune) bool {
+	switch {
+	case r == 0x00A9, r == 0x00AE, r == 0x203C, r == 0x2049, r == 0x2122,
+		r == 0x2139, r == 0x2328, r == 0x23CF, r == 0x24C2, r == 0x25B6,
+		r == 0x25C0, r == 0x2B50, r == 0x2B55, r == 0x3030, r == 0x303D,
+		r == 0x3297, r == 0x3299:
+		return true
+	case r >= 0x2194 && r <= 0x2199, r >= 0x21A9 && r <= 0x21AA,
+		r >= 0x231A && r <= 0x231B, r >= 0x23E9 && r <= 0x23F3,
+		r >= 0x23F8 && r <= 0x23FA, r >= 0x25AA && r <= 0x25AB,
+		r >= 0x25FB && r <= 0x25FE, r >= 0x2600 && r <= 0x27BF,
+		r >= 0x2934 && r <= 0x2935, r >= 0x2B05 && r <= 0x2B07,
+		r >= 0x2B1B && r <= 0x2B1C, r >= 0x1F000 && r <= 0x1FAFF,
+		r >= 0x1FC00 && r <= 0x1FFFD:
+		return true
+	}
+	return false
+}
+
+// Next returns the length in bytes of the first grapheme cluster in s
+func Next(s string) int {
+	if s == "" {
+		return 0
+	}
+
+	r, size := utf8.DecodeRuneInString(s)
+	prev := propertyOf(r)
+	n := size
+	pictographicSeq := prev == pictographic
+	regionalCount := 0
+	if prev == regionalIndicator {
+		regionalCount = 1
+	}
+
+	for n < len(s) {
+		r, size = utf8.DecodeRuneInString(s[n:])
+		cur := propertyOf(r)
+		if isBoundary(prev, cur, pictographicSeq, regionalCount) {
+			break
+		}
+
+		switch {
+		case cur == regionalIndicator:
+			regionalCount++
+		case cur == pictographic:
+			pictographicSeq = true
+		case cur != extend && cur != zwj:
+			pictographicSeq = false
+		}
+		prev = cur
+		n += size
+	}
+	return n
+}
+
+// isBoundary reports whether there is a cluster boundary between two
+// characters. pictographicSeq is true when the characters before cur form an
+// emoji followed by Extend characters and ZWJs; regionalCount is the number
+// of consecutive regional indicators before cur
+func isBoundary(prev, cur property, pictographicSeq bool, regionalCount int) bool {
+	switch {
+	case prev == cr && cur == lf: // GB3
+		return false
+	case prev == cr, prev == lf, prev == control: // GB4
+		return true
+	case cur == cr, cur == lf, cur == control: // GB5
+		return true
+	case prev == hangulL && (cur == hangulL || cur == hangulV || cur == hangulLV || cur == hangulLVT): // GB6
+		return false
+	case (prev == hangulLV || prev == hangulV) && (cur == hangulV || cur == hangulT): // GB7
+		return false
+	case (prev == hangulLVT || prev == hangulT) && cur == hangulT: // GB8
+		return false
+	case cur == extend, cur == zwj, cur == spacingMark: // GB9, GB9a
+		return false
+	case prev == zwj && cur == pictographic && pictographicSeq: // GB11
+		return false
+	case prev == regionalIndicator && cur == regionalIndicator: // GB12, GB13
+		return regionalCount%2 == 0
+	}
+	return true // GB999
+}
+
+// IsEmoji reports whether the cluster is an emoji, i.e. whether it starts
+// with a pictographic character or a regional indicator, or is a keycap: a
+// digit, '#' or '*' followed by U+FE0F VARIATION SELECTOR-16 and/or U+20E3
+// COMBINING ENCLOSING KEYCAP. This includes sequences such as flags, skin
+// tones and ZWJ sequences
+func IsEmoji(cluster string) bool {
+	r, size := utf8.DecodeRuneInString(cluster)
+	switch propertyOf(r) {
+	case pictographic, regionalIndicator:
+		return true
+	}
+	if !strings.ContainsRune("0123456789#*", r) {
+		return false
+	}
+	switch cluster[size:] {
+	case "\ufe0f", "\u20e3", "\ufe0f\u20e3":
+		return true
+	}
+	return false
+}
+
+// Split returns the grapheme clusters of s
+func Split(s string) []string {
+	var result []string
+	for len(s) > 0 {
+		n := Next(s)
+		result = append(result, s[:n])
+		s = s[n:]
+	}
+	return result
+}
@@ -0,0 +1,73 @@
+package grapheme
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		description string
+		text        string
+		expected    []string
+	}{
+		{"ASCII", "abc", []string{"a", "b", "c"}},
+		{"CR LF", "a\r\nb", []string{"a", "\r\n", "b"}},
+		{"Combining mark", "cafe\u0301!", []string{"c", "a", "f", "e\u0301", "!"}},
+		{"Hangul jamo", "각가", []string{"각", "가"}},
+		{"Skin tone modifier", "\U0001f44d\U0001f3fdx", []string{"\U0001f44d\U0001f3fd", "x"}},
+		{
+			"ZWJ sequence",
+			"\U0001f468\u200d\U0001f469\u200d\U0001f467!",
+			[]string{"\U0001f468\u200d\U0001f469\u200d\U0001f467", "!"},
+		},
+		{"Variation selector", "❤\ufe0f❤", []string{"❤\ufe0f", "❤"}},
+		{
+			"Regional indicator pairs",
+			"\U0001f1ef\U0001f1f5\U0001f1fa\U0001f1f8\U0001f1eb",
+			[]string{"\U0001f1ef\U0001f1f5", "\U0001f1fa\U0001f1f8", "\U0001f1eb"},
+		},
+		{"ZWJ after non-emoji", "a\u200d\U0001f469", []string{"a\u200d", "\U0001f469"}},
+	}
+
+	for _, test := range tests {
+		actual := Split(test.text)
+		if fmt.Sprintf("%q", actual) != fmt.Sprintf("%q", test.expected) {
+			t.Errorf(
+				"Split returned incorrect value for test [%s]. Expected:%q Got:%q",
+				test.description,
+				test.expected,
+				actual,
+			)
+		}
+	}
+}
+
+func TestIsEmoji(t *testing.T) {
+	tests := []struct {
+		cluster  string
+		expected bool
+	}{
+		{"\U0001f431", true},
+		{"\U0001f44d\U0001f3fd", true},
+		{"\U0001f468\u200d\U0001f469\u200d\U0001f467", true},
+		{"\U0001f1ef\U0001f1f5", true},
+		{"❤\ufe0f", true},
+		{"1\ufe0f\u20e3", true},
+		{"#\u20e3", true},
+		{"*\ufe0f", true},
+		{"1", false},
+		{"a\ufe0f", false},
+		{"1\ufe0f\u20e3\u0301", false},
+		{"a", false},
+		{"e\u0301", false},
+		{"日", false},
+		{"", false},
+	}
+
+	for _, test := range tests {
+		if actual := IsEmoji(test.cluster); actual != test.expected {
+			t.Errorf("IsEmoji returned incorrect value for %q. Expected:%v Got:%v", test.cluster, test.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/interspace/byte-text-go/extract"
+	"github.com/interspace/byte-text-go/validate"
+)
+
+type textRequest struct {
+	Text string `json:"text"`
+}
+
+type extractResponse struct {
+	Entities []*extract.ByteEntity `json:"entities"`
+}
+
+type validateTextRequest struct {
+	Text                 string `json:"text"`
+	MaxLength            int    `json:"max_length"`
+	CanBeEmpty           bool   `json:"can_be_empty"`
+	AllErrors            bool   `json:"all_errors"`
+	ExcludeReplyMentions bool   `json:"exclude_reply_mentions"`
+	MaxReplyMentions     int    `json:"max_reply_mentions"`
+}
+
+type validateURLRequest struct {
+	Text              string   `json:"text"`
+	RequireProtocol   bool     `json:"require_protocol"`
+	AllowUnicode      bool     `json:"allow_unicode"`
+	CheckASCIIHost    bool     `json:"check_ascii_host"`
+	RejectSpoofedHost bool     `json:"reject_spoofed_host"`
+	ProtectedDomains  []string `json:"protected_domains"`
+}
+
+type validateResponse struct {
+	Valid bool   `json:"valid"`
+	Error *Error `json:"error,omitempty"`
+}
+
+type lengthRequest struct {
+	Text              string `json:"text"`
+	MaxWeightedLength int    `json:"max_weighted_length"`
+}
+
+type lengthResponse struct {
+	Length         int           `json:"length"`
+	WeightedLength int           `json:"weighted_length"`
+	Permillage     int           `json:"permillage"`
+	Valid          bool          `json:"valid"`
+	DisplayRange   extract.Range `json:"display_range"`
+	ValidRange     extract.Range `json:"valid_range"`
+	ValidByteRange extract.Range `json:"valid_byte_range"`
+}
+
+func (h *handler) endpoints() map[string]endpoint {
+	return map[string]endpoint{
+		"/extract": {
+			newRequest: func() interface{} { return &textRequest{} },
+			serve:      h.extract,
+		},
+		"/validate/text": {
+			// Fields missing from the request keep these defaults
+			newRequest: func() interface{} {
+				return &validateTextRequest{MaxLength: h.config.MaxTextLength}
+			},
+			serve: h.validateText,
+		},
+		"/validate/username": {
+			newRequest: func() interface{} { return &textRequest{} },
+			serve:      h.validateUsername,
+		},
+		"/validate/hashtag": {
+			newRequest: func() interface{} { return &textRequest{} },
+			serve:      h.validateHashtag,
+		},
+		"/validate/url": {
+			newRequest: func() interface{} { return &validateURLRequest{} },
+			check:      checkValidateURL,
+			serve:      h.validateURL,
+		},
+		"/length": {
+			newRequest: func() interface{} {
+				return &lengthRequest{MaxWeightedLength: h.config.ParseConfig.MaxWeightedLength}
+			},
+			serve: h.length,
+		},
+	}
+}
+
+func (h *handler) extract(request interface{}) interface{} {
+	r := request.(*textRequest)
+	entities := h.config.Extractor.Entities(r.Text)
+	if entities == nil {
+		entities = []*extract.ByteEntity{}
+	}
+	return extractResponse{Entities: entities}
+}
+
+func (h *handler) validateText(request interface{}) interface{} {
+	r := request.(*validateTextRequest)
+	args := validate.ValidationArgs{
+		MaxLength:  r.MaxLength,
+		CanBeEmpty: r.CanBeEmpty,
+		AllErrors:  r.AllErrors,
+		Length:     validate.LengthArgs{MaxReplyMentions: r.MaxReplyMentions},
+	}
+	if r.ExcludeReplyMentions {
+		args.Length.Mode = validate.ExcludeReplyMentions
+	}
+	return newValidateResponse(validate.TextValidate(r.Text, args))
+}
+
+func (h *handler) validateUsername(request interface{}) interface{} {
+	r := request.(*textRequest)
+	return newValidateResponse(validate.UsernameValidate(r.Text))
+}
+
+func (h *handler) validateHashtag(request interface{}) interface{} {
+	r := request.(*textRequest)
+	return newValidateResponse(validate.HashtagValidate(r.Text))
+}
+
+func (h *handler) validateURL(request interface{}) interface{} {
+	r := request.(*validateURLRequest)
+	_, err := validate.ParseURL(r.Text, validate.URLArgs{
+		RequireProtocol:   r.RequireProtocol,
+		AllowUnicode:      r.AllowUnicode,
+		CheckASCIIHost:    r.CheckASCIIHost,
+		RejectSpoofedHost: r.RejectSpoofedHost,
+		ProtectedDomains:  r.ProtectedDomains,
+	})
+	return newValidateResponse(err)
+}
+
+// checkValidateURL rejects protected domains which are not valid
+// internationalized domain names, which are an error in the request rather
+// than in the URL
+func checkValidateURL(request interface{}) error {
+	r := request.(*validateURLRequest)
+	for _, domain := range r.ProtectedDomains {
+		ascii, err := extract.HostToASCII(domain)
+		if err == nil {
+			_, err = extract.HostToUnicode(ascii)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid protected domain %q", domain)
+		}
+	}
+	return nil
+}
+
+func newValidateResponse(err error) validateResponse {
+	return validateResponse{Valid: err == nil, Error: newError(err)}
+}
+
+func (h *handler) length(request interface{}) interface{} {
+	r := request.(*lengthRequest)
+	config := h.config.ParseConfig
+	config.MaxWeightedLength = r.MaxWeightedLength
+	parsed := validate.ParseText(r.Text, config)
+	return lengthResponse{
+		Length:         validate.TextLength(r.Text),
+		WeightedLength: parsed.WeightedLength,
+		Permillage:     parsed.Permillage,
+		Valid:          parsed.IsValid,
+		DisplayRange:   parsed.DisplayRange,
+		ValidRange:     parsed.ValidRange,
+		ValidByteRange: parsed.ValidByteRange,
+	}
+}
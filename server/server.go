@@ -0,0 +1,284 @@
+// Package server exposes the extract and validate packages as a JSON HTTP
+// service, so that services written in other languages can apply the same
+// rules.
+//
+// Every endpoint accepts a POST with a JSON object and responds with a JSON
+// object. A JSON array of request objects may be posted instead, in which
+// case the response is an array holding the result for each request, in
+// order. The endpoints are:
+//
+//	/extract            {"text"}                                      -> {"entities"}
+//	/validate/text      {"text", "max_length", "can_be_empty",        -> {"valid", "error"}
+//	                     "all_errors", "exclude_reply_mentions",
+//	                     "max_reply_mentions"}
+//	/validate/username  {"text"}                                      -> {"valid", "error"}
+//	/validate/hashtag   {"text"}                                      -> {"valid", "error"}
+//	/validate/url       {"text", "require_protocol", "allow_unicode", -> {"valid", "error"}
+//	                     "check_ascii_host", "reject_spoofed_host",
+//	                     "protected_domains"}
+//	/length             {"text", "max_weighted_length"}               -> {"length", "weighted_length", ...}
+//
+// Requests which cannot be served are answered with a 4xx status and an
+// {"error"} body.
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/interspace/byte-text-go/extract"
+	"github.com/interspace/byte-text-go/validate"
+)
+
+// Config configures the handler returned by NewHandler. Callers should start
+// from DefaultConfig and override individual fields
+type Config struct {
+	// The maximum size of a request body in bytes
+	MaxRequestBytes int64
+
+	// The maximum number of requests in a batch
+	MaxBatchSize int
+
+	// The extractor used by /extract. A nil value uses the default rules
+	Extractor *extract.Extractor
+
+	// The maximum length used by /validate/text when a request has no
+	// max_length. It is compared with the unweighted length of the text (See:
+	// validate.TextLength), unlike ParseConfig.MaxWeightedLength
+	MaxTextLength int
+
+	// The configuration used by /length. The max_weighted_length field of a
+	// request overrides its MaxWeightedLength
+	ParseConfig validate.ParseConfig
+}
+
+// DefaultConfig returns a configuration accepting requests of up to 1MB and
+// batches of up to 100 requests, and texts of up to 280 characters
+func DefaultConfig() Config {
+	return Config{
+		MaxRequestBytes: 1 << 20,
+		MaxBatchSize:    100,
+		MaxTextLength:   280,
+		ParseConfig:     validate.DefaultParseConfig(),
+	}
+}
+
+// Error is the JSON representation of an error. Type is one of the error
+// type constants below; the other fields are only set for the types they
+// apply to. A validate.ValidationErrors is represented with the type
+// "errors", and each of its errors in Errors
+type Error struct {
+	Type       string  `json:"type"`
+	Message    string  `json:"message"`
+	Length     *int    `json:"length,omitempty"`
+	MinLength  *int    `json:"min_length,omitempty"`
+	MaxLength  *int    `json:"max_length,omitempty"`
+	Excluded   *int    `json:"excluded,omitempty"`
+	Character  string  `json:"character,omitempty"`
+	Offset     *int    `json:"offset,omitempty"`
+	RuneOffset *int    `json:"rune_offset,omitempty"`
+	Component  string  `json:"component,omitempty"`
+	Value      *string `json:"value,omitempty"`
+
+	Spoofing *extract.HostSpoofing `json:"spoofing,omitempty"`
+	Errors   []*Error              `json:"errors,omitempty"`
+}
+
+// Error types. The first group mirrors the error types of the validate
+// package; the second describes requests that could not be served
+const (
+	ErrorTooLong           = "too_long"
+	ErrorTooShort          = "too_short"
+	ErrorEmpty             = "empty"
+	ErrorInvalidCharacter  = "invalid_character"
+	ErrorMissingPrefix     = "missing_prefix"
+	ErrorLeadingSeparator  = "leading_separator"
+	ErrorTrailingSeparator = "trailing_separator"
+	ErrorExtraSeparator    = "extra_separator"
+	ErrorNumericHashtag    = "numeric_hashtag"
+	ErrorInvalidURL        = "invalid_url"
+	ErrorSpoofedHost       = "spoofed_host"
+	ErrorValidationErrors  = "errors"
+	ErrorInvalid           = "invalid"
+
+	ErrorBadRequest       = "bad_request"
+	ErrorMethodNotAllowed = "method_not_allowed"
+	ErrorTooLarge         = "request_too_large"
+	ErrorNotFound         = "not_found"
+)
+
+// newError converts an error returned by the validate package
+func newError(err error) *Error {
+	switch err := err.(type) {
+	case nil:
+		return nil
+	case validate.TooLongError:
+		length, maxLength, excluded := err.Length(), err.MaxLength(), err.Excluded()
+		return &Error{
+			Type:      ErrorTooLong,
+			Message:   err.Error(),
+			Length:    &length,
+			MaxLength: &maxLength,
+			Excluded:  &excluded,
+		}
+	case validate.EmptyError:
+		return &Error{Type: ErrorEmpty, Message: err.Error()}
+	case validate.TooShortError:
+		length, minLength := err.Length(), err.MinLength()
+		return &Error{Type: ErrorTooShort, Message: err.Error(), Length: &length, MinLength: &minLength}
+	case validate.InvalidCharacterError:
+		offset, runeOffset := err.Offset, err.RuneOffset
+		return &Error{
+			Type:       ErrorInvalidCharacter,
+			Message:    err.Error(),
+			Character:  string(err.Character),
+			Offset:     &offset,
+			RuneOffset: &runeOffset,
+		}
+	case validate.MissingPrefixError:
+		return &Error{Type: ErrorMissingPrefix, Message: err.Error(), Character: string(err.Prefix)}
+	case validate.SeparatorError:
+		errorType := map[validate.SeparatorPosition]string{
+			validate.LeadingSeparator:  ErrorLeadingSeparator,
+			validate.TrailingSeparator: ErrorTrailingSeparator,
+			validate.ExtraSeparator:    ErrorExtraSeparator,
+		}[err.Position]
+		offset := err.Offset
+		return &Error{Type: errorType, Message: err.Error(), Character: string(err.Character), Offset: &offset}
+	case validate.NumericHashtagError:
+		return &Error{Type: ErrorNumericHashtag, Message: err.Error()}
+	case validate.InvalidURLComponentError:
+		value := err.Value
+		return &Error{Type: ErrorInvalidURL, Message: err.Error(), Component: string(err.Component), Value: &value}
+	case validate.SpoofedHostError:
+		host, spoofing := err.Host, err.Spoofing
+		return &Error{Type: ErrorSpoofedHost, Message: err.Error(), Value: &host, Spoofing: &spoofing}
+	case validate.ValidationErrors:
+		errs := make([]*Error, len(err))
+		for i, e := range err {
+			errs[i] = newError(e)
+		}
+		return &Error{Type: ErrorValidationErrors, Message: err.Error(), Errors: errs}
+	}
+	return &Error{Type: ErrorInvalid, Message: err.Error()}
+}
+
+type errorResponse struct {
+	Error *Error `json:"error"`
+}
+
+// endpoint serves a single request. The request body is decoded into the
+// value returned by newRequest, which is then passed to check, if set, and
+// to serve. Requests which check rejects are answered with a 400 status
+type endpoint struct {
+	newRequest func() interface{}
+	check      func(request interface{}) error
+	serve      func(request interface{}) interface{}
+}
+
+type handler struct {
+	config Config
+}
+
+// NewHandler returns an http.Handler serving the endpoints described in the
+// package documentation
+func NewHandler(config Config) http.Handler {
+	if config.Extractor == nil {
+		config.Extractor, _ = extract.NewExtractor(extract.DefaultExtractorOptions())
+	}
+
+	h := &handler{config: config}
+	mux := http.NewServeMux()
+	for path, e := range h.endpoints() {
+		mux.Handle(path, h.serveEndpoint(e))
+	}
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, ErrorNotFound, fmt.Sprintf("no endpoint %s", r.URL.Path))
+	})
+	return mux
+}
+
+func (h *handler) serveEndpoint(e endpoint) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeError(w, http.StatusMethodNotAllowed, ErrorMethodNotAllowed, "only POST is supported")
+			return
+		}
+
+		// Read one byte more than allowed to detect oversized bodies
+		body, err := ioutil.ReadAll(io.LimitReader(r.Body, h.config.MaxRequestBytes+1))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, ErrorBadRequest, err.Error())
+			return
+		}
+		if int64(len(body)) > h.config.MaxRequestBytes {
+			writeError(w, http.StatusRequestEntityTooLarge, ErrorTooLarge,
+				fmt.Sprintf("request body exceeds %d bytes", h.config.MaxRequestBytes))
+			return
+		}
+
+		body = bytes.TrimSpace(body)
+		if len(body) == 0 || body[0] != '[' {
+			request := e.newRequest()
+			if err := e.decode(body, request); err != nil {
+				writeError(w, http.StatusBadRequest, ErrorBadRequest, err.Error())
+				return
+			}
+			writeJSON(w, http.StatusOK, e.serve(request))
+			return
+		}
+
+		var batch []json.RawMessage
+		if err := json.Unmarshal(body, &batch); err != nil {
+			writeError(w, http.StatusBadRequest, ErrorBadRequest, err.Error())
+			return
+		}
+		if len(batch) > h.config.MaxBatchSize {
+			writeError(w, http.StatusRequestEntityTooLarge, ErrorTooLarge,
+				fmt.Sprintf("batch of %d requests exceeds %d", len(batch), h.config.MaxBatchSize))
+			return
+		}
+		responses := make([]interface{}, len(batch))
+		for i, raw := range batch {
+			request := e.newRequest()
+			if err := e.decode(raw, request); err != nil {
+				writeError(w, http.StatusBadRequest, ErrorBadRequest, fmt.Sprintf("request %d: %v", i, err))
+				return
+			}
+			responses[i] = e.serve(request)
+		}
+		writeJSON(w, http.StatusOK, responses)
+	})
+}
+
+// decode decodes a single JSON object, rejecting unknown fields so that
+// misspelled options are not silently ignored, and checks the request
+func (e endpoint) decode(data []byte, v interface{}) error {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.DisallowUnknownFields()
+	if err := d.Decode(v); err != nil {
+		return err
+	}
+	if d.More() {
+		return fmt.Errorf("unexpected data after request")
+	}
+	if e.check != nil {
+		return e.check(v)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, errorType, message string) {
+	writeJSON(w, status, errorResponse{Error: &Error{Type: errorType, Message: message}})
+}
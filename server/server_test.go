@@ -0,0 +1,242 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	config := DefaultConfig()
+	config.MaxRequestBytes = 200
+	config.MaxBatchSize = 2
+	config.MaxTextLength = 10
+	handler := NewHandler(config)
+
+	tests := []struct {
+		description string
+		method      string
+		path        string
+		body        string
+		status      int
+		response    string
+	}{
+		{
+			description: "extract",
+			path:        "/extract",
+			body:        `{"text": "hi @User_1"}`,
+			status:      http.StatusOK,
+			response:    `{"entities":[{"type":"Mention","text":"@User_1","indices":[3,10],"byte_indices":[3,10],"utf16_indices":[3,10],"screen_name":"User_1","screen_name_key":"userl"}]}`,
+		},
+		{
+			description: "extract nothing",
+			path:        "/extract",
+			body:        `{"text": "hi"}`,
+			status:      http.StatusOK,
+			response:    `{"entities":[]}`,
+		},
+		{
+			description: "valid text",
+			path:        "/validate/text",
+			body:        `{"text": "hello"}`,
+			status:      http.StatusOK,
+			response:    `{"valid":true}`,
+		},
+		{
+			description: "text too long",
+			path:        "/validate/text",
+			body:        `{"text": "hello", "max_length": 3}`,
+			status:      http.StatusOK,
+			response:    `{"valid":false,"error":{"type":"too_long","message":"Length 5 exceeds 3 characters","length":5,"max_length":3,"excluded":0}}`,
+		},
+		{
+			description: "text too long by default",
+			path:        "/validate/text",
+			body:        `{"text": "hello world"}`,
+			status:      http.StatusOK,
+			response:    `{"valid":false,"error":{"type":"too_long","message":"Length 11 exceeds 10 characters","length":11,"max_length":10,"excluded":0}}`,
+		},
+		{
+			description: "text too long excluding reply mentions",
+			path:        "/validate/text",
+			body:        `{"text": "@alice hello", "max_length": 3, "exclude_reply_mentions": true}`,
+			status:      http.StatusOK,
+			response:    `{"valid":false,"error":{"type":"too_long","message":"Length 5 exceeds 3 characters (7 excluded)","length":5,"max_length":3,"excluded":7}}`,
+		},
+		{
+			description: "all errors",
+			path:        "/validate/text",
+			body:        `{"text": "hello\ufffe", "max_length": 3, "all_errors": true}`,
+			status:      http.StatusOK,
+			response:    "{\"valid\":false,\"error\":{\"type\":\"errors\",\"message\":\"Length 6 exceeds 3 characters; Invalid character [\ufffe] found at byte offset 5\",\"errors\":[{\"type\":\"too_long\",\"message\":\"Length 6 exceeds 3 characters\",\"length\":6,\"max_length\":3,\"excluded\":0},{\"type\":\"invalid_character\",\"message\":\"Invalid character [\ufffe] found at byte offset 5\",\"character\":\"\ufffe\",\"offset\":5,\"rune_offset\":5}]}}",
+		},
+		{
+			description: "empty text",
+			path:        "/validate/text",
+			body:        `{"text": ""}`,
+			status:      http.StatusOK,
+			response:    `{"valid":false,"error":{"type":"empty","message":"Text may not be empty"}}`,
+		},
+		{
+			description: "empty text allowed",
+			path:        "/validate/text",
+			body:        `{"text": "", "can_be_empty": true}`,
+			status:      http.StatusOK,
+			response:    `{"valid":true}`,
+		},
+		{
+			description: "invalid character",
+			path:        "/validate/text",
+			body:        `{"text": "a\ufffeb"}`,
+			status:      http.StatusOK,
+			response:    "{\"valid\":false,\"error\":{\"type\":\"invalid_character\",\"message\":\"Invalid character [\ufffe] found at byte offset 1\",\"character\":\"\ufffe\",\"offset\":1,\"rune_offset\":1}}",
+		},
+		{
+			description: "username",
+			path:        "/validate/username",
+			body:        `[{"text": "@jack"}, {"text": "jack"}]`,
+			status:      http.StatusOK,
+			response:    `[{"valid":true},{"valid":false,"error":{"type":"missing_prefix","message":"Text must start with [@]","character":"@"}}]`,
+		},
+		{
+			description: "username separator",
+			path:        "/validate/username",
+			body:        `{"text": "@jack_"}`,
+			status:      http.StatusOK,
+			response:    `{"valid":false,"error":{"type":"trailing_separator","message":"Invalid trailing separator [_] found at byte offset 5","character":"_","offset":5}}`,
+		},
+		{
+			description: "username too short",
+			path:        "/validate/username",
+			body:        `{"text": "@jo"}`,
+			status:      http.StatusOK,
+			response:    `{"valid":false,"error":{"type":"too_short","message":"Length 2 is less than 3 characters","length":2,"min_length":3}}`,
+		},
+		{
+			description: "hashtag",
+			path:        "/validate/hashtag",
+			body:        `{"text": "#go"}`,
+			status:      http.StatusOK,
+			response:    `{"valid":true}`,
+		},
+		{
+			description: "numeric hashtag",
+			path:        "/validate/hashtag",
+			body:        `{"text": "#2024"}`,
+			status:      http.StatusOK,
+			response:    `{"valid":false,"error":{"type":"numeric_hashtag","message":"Hashtag must contain a letter"}}`,
+		},
+		{
+			description: "URL without a required protocol",
+			path:        "/validate/url",
+			body:        `{"text": "example.com", "require_protocol": true}`,
+			status:      http.StatusOK,
+			response:    `{"valid":false,"error":{"type":"invalid_url","message":"Invalid URL scheme []","component":"scheme","value":""}}`,
+		},
+		{
+			description: "URL",
+			path:        "/validate/url",
+			body:        `{"text": "example.com"}`,
+			status:      http.StatusOK,
+			response:    `{"valid":true}`,
+		},
+		{
+			description: "URL with an invalid punycode host",
+			path:        "/validate/url",
+			body:        `{"text": "http://xn--abc.com/", "check_ascii_host": true}`,
+			status:      http.StatusOK,
+			response:    `{"valid":false,"error":{"type":"invalid_url","message":"Invalid URL host [xn--abc.com]","component":"host","value":"xn--abc.com"}}`,
+		},
+		{
+			description: "spoofed URL host",
+			path:        "/validate/url",
+			body:        `{"text": "http://pаypal.com/", "allow_unicode": true, "reject_spoofed_host": true, "protected_domains": ["paypal.com"]}`,
+			status:      http.StatusOK,
+			response:    `{"valid":false,"error":{"type":"spoofed_host","message":"URL host [pаypal.com] looks like [paypal.com]","value":"pаypal.com","spoofing":{"level":"Minimally Restrictive","mixed_script":true,"lookalike":"paypal.com"}}}`,
+		},
+		{
+			description: "invalid protected domain",
+			path:        "/validate/url",
+			body:        `{"text": "http://paypal.com/", "reject_spoofed_host": true, "protected_domains": ["xn--abc.com"]}`,
+			status:      http.StatusBadRequest,
+			response:    `{"error":{"type":"bad_request","message":"invalid protected domain \"xn--abc.com\""}}`,
+		},
+		{
+			description: "length",
+			path:        "/length",
+			body:        `{"text": "hello", "max_weighted_length": 4}`,
+			status:      http.StatusOK,
+			response:    `{"length":5,"weighted_length":5,"permillage":1250,"valid":false,"display_range":[0,5],"valid_range":[0,4],"valid_byte_range":[0,4]}`,
+		},
+		{
+			description: "unknown field",
+			path:        "/validate/text",
+			body:        `{"text": "hello", "maxLength": 3}`,
+			status:      http.StatusBadRequest,
+			response:    `{"error":{"type":"bad_request","message":"json: unknown field \"maxLength\""}}`,
+		},
+		{
+			description: "invalid batch entry",
+			path:        "/extract",
+			body:        `[{"text": "a"}, 5]`,
+			status:      http.StatusBadRequest,
+			response:    `{"error":{"type":"bad_request","message":"request 1: json: cannot unmarshal number into Go value of type server.textRequest"}}`,
+		},
+		{
+			description: "batch too large",
+			path:        "/extract",
+			body:        `[{}, {}, {}]`,
+			status:      http.StatusRequestEntityTooLarge,
+			response:    `{"error":{"type":"request_too_large","message":"batch of 3 requests exceeds 2"}}`,
+		},
+		{
+			description: "body too large",
+			path:        "/extract",
+			body:        `{"text": "` + strings.Repeat("a", 200) + `"}`,
+			status:      http.StatusRequestEntityTooLarge,
+			response:    `{"error":{"type":"request_too_large","message":"request body exceeds 200 bytes"}}`,
+		},
+		{
+			description: "wrong method",
+			method:      http.MethodGet,
+			path:        "/extract",
+			status:      http.StatusMethodNotAllowed,
+			response:    `{"error":{"type":"method_not_allowed","message":"only POST is supported"}}`,
+		},
+		{
+			description: "unknown endpoint",
+			path:        "/validate",
+			body:        `{}`,
+			status:      http.StatusNotFound,
+			response:    `{"error":{"type":"not_found","message":"no endpoint /validate"}}`,
+		},
+	}
+
+	for _, test := range tests {
+		method := test.method
+		if method == "" {
+			method = http.MethodPost
+		}
+		request := httptest.NewRequest(method, test.path, strings.NewReader(test.body))
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+
+		if recorder.Code != test.status {
+			t.Errorf(
+				"Handler returned incorrect status for test [%s]. Expected:%v Got:%v",
+				test.description,
+				test.status,
+				recorder.Code,
+			)
+		}
+		if response := strings.TrimSpace(recorder.Body.String()); response != test.response {
+			t.Errorf(
+				"Handler returned incorrect response for test [%s]. Expected:%s Got:%s",
+				test.description,
+				test.response,
+				response,
+			)
+		}
+	}
+}
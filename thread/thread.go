@@ -0,0 +1,232 @@
+// Package thread provides routines for splitting long texts into a thread
+// of posts which are each valid on their own
+package thread
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/interspace/byte-text-go/extract"
+	"github.com/interspace/byte-text-go/internal/grapheme"
+	"github.com/interspace/byte-text-go/validate"
+)
+
+// Args configures Split
+type Args struct {
+	// The rules every part must pass
+	Validation validate.ValidationArgs
+
+	// When set, a " i/n" counter is appended to every part. The counter is
+	// included in the length of the part. No counter is added if the text
+	// fits in a single part once leading and trailing whitespace is removed
+	Counters bool
+}
+
+// Part is a single post of a thread
+type Part struct {
+	Text string
+
+	// The entities within Text. Their offsets are relative to Text
+	Entities []*extract.ByteEntity
+}
+
+// EntityTooLongError is returned when an entity is too long to fit in a part
+// by itself. Splitting the text would require breaking the entity
+type EntityTooLongError struct {
+	Entity *extract.ByteEntity
+}
+
+func (e EntityTooLongError) Error() string {
+	return fmt.Sprintf("%v %s is too long to fit in a single part", e.Entity.Type, e.Entity.Text)
+}
+
+// Split breaks text into parts which each pass validate.TextValidate with
+// args.Validation. Parts are broken at the end of a sentence where possible,
+// otherwise between words, and never inside an entity found by
+// extract.Entities. Leading and trailing whitespace is removed from each
+// part. The length of each part is counted following
+// args.Validation.Length, so a reply prefix left out by
+// validate.ExcludeReplyMentions does not use up the first part.
+//
+// Returns an error if the text is invalid for a reason other than its length
+// (e.g. it contains invalid characters), or if an entity does not fit in a
+// part.
+func Split(text string, args Args) ([]Part, error) {
+	if err := validate.TextValidate(text, args.Validation); err == nil {
+		return []Part{{Text: text, Entities: extract.Entities(text)}}, nil
+	} else if !isTooLong(err) {
+		return nil, err
+	}
+
+	entities := extract.Entities(text)
+
+	// The text may fit in a single part once trimmed, and then needs no
+	// counter
+	ranges, err := splitRanges(text, entities, args.Validation.MaxLength, args.Validation.Length)
+	if err != nil {
+		return nil, err
+	}
+	if !args.Counters || len(ranges) == 1 {
+		return newParts(text, entities, ranges, args)
+	}
+
+	// The length of the counters depends on the number of parts, so split
+	// again until the number of digits needed is stable
+	total := len(ranges)
+	for {
+		budget := args.Validation.MaxLength
+		if args.Counters {
+			budget -= len(counter(total, total))
+		}
+
+		ranges, err := splitRanges(text, entities, budget, args.Validation.Length)
+		if err != nil {
+			return nil, err
+		}
+		if !args.Counters || len(fmt.Sprint(len(ranges))) <= len(fmt.Sprint(total)) {
+			return newParts(text, entities, ranges, args)
+		}
+		total = len(ranges)
+	}
+}
+
+// isTooLong reports whether the length of the text is its only problem
+func isTooLong(err error) bool {
+	switch err := err.(type) {
+	case validate.TooLongError:
+		return true
+	case validate.ValidationErrors:
+		return len(err) == 1 && isTooLong(err[0])
+	}
+	return false
+}
+
+func counter(i, n int) string {
+	return fmt.Sprintf(" %d/%d", i, n)
+}
+
+// splitRanges returns the byte ranges of the parts of text, each of which is
+// at most budget characters long when counted following lengthArgs
+func splitRanges(text string, entities []*extract.ByteEntity, budget int, lengthArgs validate.LengthArgs) ([]extract.Range, error) {
+	var ranges []extract.Range
+	start := skipSpace(text, 0)
+	for start < len(text) {
+		// The characters the length mode leaves out at the start of the
+		// part, such as its reply prefix, do not use up the budget. A part
+		// which ends before the end of the prefix is shorter still
+		_, excluded := validate.TextLengthWithArgs(text[start:], lengthArgs)
+
+		// Find the longest run of grapheme clusters that fits
+		limit := start
+		length := 0
+		for limit < len(text) {
+			n := grapheme.Next(text[limit:])
+			l := validate.TextLength(text[limit : limit+n])
+			if length+l > budget+excluded {
+				break
+			}
+			length += l
+			limit += n
+		}
+
+		end := limit
+		if limit < len(text) {
+			end = breakPosition(text, entities, start, limit)
+			if end <= start {
+				for _, e := range entities {
+					if e.ByteRange.Start <= start && start < e.ByteRange.Stop {
+						return nil, EntityTooLongError{Entity: e}
+					}
+				}
+				// Only reachable with a budget too small for a single
+				// character
+				return nil, fmt.Errorf("thread: part length %d is too small", budget)
+			}
+		}
+
+		ranges = append(ranges, extract.Range{
+			Start: start,
+			Stop:  len(strings.TrimRightFunc(text[:end], unicode.IsSpace)),
+		})
+		start = skipSpace(text, end)
+	}
+	return ranges, nil
+}
+
+// breakPosition returns the byte offset at which to end a part that starts at
+// start and may extend at most up to limit. The end of a sentence is
+// preferred if it is in the second half of the part, then a break between
+// words, then the last grapheme boundary outside of an entity
+func breakPosition(text string, entities []*extract.ByteEntity, start, limit int) int {
+	sentence, word := -1, -1
+	for i, r := range text[start:limit] {
+		if i == 0 || !unicode.IsSpace(r) {
+			continue
+		}
+		pos := start + i
+		word = pos
+		if prev, _ := utf8.DecodeLastRuneInString(text[:pos]); strings.ContainsRune(".!?…", prev) {
+			sentence = pos
+		}
+	}
+	// A space right after the limit is also a valid place to break
+	if next, _ := utf8.DecodeRuneInString(text[limit:]); unicode.IsSpace(next) {
+		word = limit
+		if prev, _ := utf8.DecodeLastRuneInString(text[:limit]); strings.ContainsRune(".!?…", prev) {
+			sentence = limit
+		}
+	}
+
+	switch {
+	case sentence > start+(limit-start)/2:
+		return sentence
+	case word > start:
+		return word
+	}
+
+	for _, e := range entities {
+		if e.ByteRange.Start < limit && limit < e.ByteRange.Stop {
+			return e.ByteRange.Start
+		}
+	}
+	return limit
+}
+
+func skipSpace(text string, offset int) int {
+	return len(text) - len(strings.TrimLeftFunc(text[offset:], unicode.IsSpace))
+}
+
+// newParts builds the parts for the given ranges, moving the entities into
+// the parts and adding counters
+func newParts(text string, entities []*extract.ByteEntity, ranges []extract.Range, args Args) ([]Part, error) {
+	parts := make([]Part, len(ranges))
+	for i, r := range ranges {
+		parts[i].Text = text[r.Start:r.Stop]
+		if args.Counters && len(ranges) > 1 {
+			parts[i].Text += counter(i+1, len(ranges))
+		}
+
+		runeStart := extract.ByteToRuneOffset(text, r.Start)
+		utf16Start := extract.ByteToUTF16Offset(text, r.Start)
+		for _, e := range entities {
+			if e.ByteRange.Start < r.Start || e.ByteRange.Stop > r.Stop {
+				continue
+			}
+			moved := *e
+			moved.ByteRange.Start -= r.Start
+			moved.ByteRange.Stop -= r.Start
+			moved.Range.Start -= runeStart
+			moved.Range.Stop -= runeStart
+			moved.UTF16Range.Start -= utf16Start
+			moved.UTF16Range.Stop -= utf16Start
+			parts[i].Entities = append(parts[i].Entities, &moved)
+		}
+
+		if err := validate.TextValidate(parts[i].Text, args.Validation); err != nil {
+			return nil, err
+		}
+	}
+	return parts, nil
+}
@@ -0,0 +1,189 @@
+package thread
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/interspace/byte-text-go/validate"
+	goyaml "gopkg.in/yaml.v1"
+)
+
+type Conformance struct {
+	Tests map[string][]*Test
+}
+
+type Test struct {
+	Description string
+	Text        string
+	MaxLength   int `yaml:"max_length"`
+	Counters    bool
+	Expected    []string
+}
+
+var cwd, _ = os.Getwd()
+var parentDir = path.Dir(cwd)
+var threadYmlPath = path.Join(parentDir, "conformance", "thread.yml")
+
+func TestSplit(t *testing.T) {
+	contents, err := ioutil.ReadFile(threadYmlPath)
+	if err != nil {
+		t.Errorf("Error reading thread.yml: %v", err)
+		t.FailNow()
+	}
+
+	var conformance = &Conformance{}
+	err = goyaml.Unmarshal(contents, &conformance)
+	if err != nil {
+		t.Errorf("Error parsing thread.yml: %v", err)
+		t.FailNow()
+	}
+
+	splitTests, ok := conformance.Tests["split"]
+	if !ok {
+		t.Errorf("Conformance file did not contain 'split' key")
+		t.FailNow()
+	}
+
+	// The results must not depend on whether all errors are collected
+	for _, allErrors := range []bool{false, true} {
+		for _, test := range splitTests {
+			args := Args{
+				Validation: validate.ValidationArgs{
+					MaxLength: test.MaxLength,
+					AllErrors: allErrors,
+				},
+				Counters: test.Counters,
+			}
+			parts, err := Split(test.Text, args)
+			if len(test.Expected) == 0 {
+				if err == nil {
+					t.Errorf("Split did not fail for test [%s]. Got:%v\n", test.Description, parts)
+				}
+				continue
+			} else if err != nil {
+				t.Errorf("Split failed for test [%s]: %v\n", test.Description, err)
+				continue
+			}
+
+			var actual []string
+			for _, p := range parts {
+				actual = append(actual, p.Text)
+				if err := validate.TextValidate(p.Text, args.Validation); err != nil {
+					t.Errorf("Split returned invalid part for test [%s]: %v\n", test.Description, err)
+				}
+
+				for _, e := range p.Entities {
+					if p.Text[e.ByteRange.Start:e.ByteRange.Stop] != e.Text ||
+						[]rune(p.Text)[e.Range.Start] != []rune(e.Text)[0] {
+						t.Errorf(
+							"Split returned entity with incorrect offsets for test [%s]. Got:%s\n",
+							test.Description,
+							e,
+						)
+					}
+				}
+			}
+
+			if fmt.Sprintf("%q", actual) != fmt.Sprintf("%q", test.Expected) {
+				t.Errorf(
+					"Split returned incorrect value for test [%s]. Expected:%q Got:%q\n",
+					test.Description,
+					test.Expected,
+					actual,
+				)
+			}
+		}
+	}
+}
+
+func TestSplitExcludingReplyMentions(t *testing.T) {
+	tests := []struct {
+		description string
+		text        string
+		maxLength   int
+		counters    bool
+		expected    []string
+	}{
+		{
+			"Leave the reply prefix out of the length of the first part",
+			"@alice @bob @carol one two three four",
+			10,
+			false,
+			[]string{"@alice @bob @carol one two", "three four"},
+		},
+		{
+			"Fit a reply in a single part",
+			"@alice @bob @carol one two",
+			7,
+			true,
+			[]string{"@alice @bob @carol one two"},
+		},
+		{
+			"Include counters in the length of a reply",
+			"@alice @bob one two three four",
+			14,
+			true,
+			[]string{"@alice @bob one two 1/2", "three four 2/2"},
+		},
+		{
+			"Leave out the leading mentions of a later part",
+			"one two three @alice four",
+			13,
+			false,
+			[]string{"one two three", "@alice four"},
+		},
+	}
+
+	for _, test := range tests {
+		args := Args{
+			Validation: validate.ValidationArgs{
+				MaxLength: test.maxLength,
+				Length:    validate.LengthArgs{Mode: validate.ExcludeReplyMentions},
+			},
+			Counters: test.counters,
+		}
+		parts, err := Split(test.text, args)
+		if err != nil {
+			t.Errorf("Split failed for test [%s]: %v\n", test.description, err)
+			continue
+		}
+
+		var actual []string
+		for _, p := range parts {
+			actual = append(actual, p.Text)
+			if err := validate.TextValidate(p.Text, args.Validation); err != nil {
+				t.Errorf("Split returned invalid part for test [%s]: %v\n", test.description, err)
+			}
+		}
+		if fmt.Sprintf("%q", actual) != fmt.Sprintf("%q", test.expected) {
+			t.Errorf(
+				"Split returned incorrect value for test [%s]. Expected:%q Got:%q\n",
+				test.description,
+				test.expected,
+				actual,
+			)
+		}
+	}
+}
+
+func ExampleSplit() {
+	args := Args{
+		Validation: validate.ValidationArgs{MaxLength: 30},
+		Counters:   true,
+	}
+	parts, err := Split("Thanks @username! The slides are at http://example.com/slides #talk", args)
+	if err != nil {
+		panic(err)
+	}
+	for _, p := range parts {
+		fmt.Printf("%s %v\n", p.Text, p.Entities)
+	}
+	// Output:
+	// Thanks @username! 1/4 [ByteEntity{Text: [@username] Range: (7, 16) Type: Mention]
+	// The slides are at 2/4 []
+	// http://example.com/slides 3/4 [ByteEntity{Text: [http://example.com/slides] Range: (0, 25) Type: URL]
+	// #talk 4/4 [ByteEntity{Text: [#talk] Range: (0, 5) Type: Hashtag]
+}
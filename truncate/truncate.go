@@ -0,0 +1,71 @@
+// Package truncate provides routines for shortening texts without breaking
+// the entities found by the extract package
+package truncate
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/interspace/byte-text-go/extract"
+	"github.com/interspace/byte-text-go/internal/grapheme"
+	"github.com/interspace/byte-text-go/validate"
+)
+
+// Truncate shortens text so that its length, as counted by
+// validate.TextLength, including the ellipsis does not exceed limit.
+//
+// The text is only ever cut between grapheme clusters, so emoji sequences
+// and combining characters stay intact, and an entity (a mention, hashtag,
+// cashtag or URL) is either kept whole or dropped entirely. Trailing
+// whitespace is removed before the ellipsis is appended. If the text already
+// fits it is returned unchanged; if the ellipsis alone does not fit it is
+// omitted.
+//
+// The entities of the returned text are returned along with it. Because only
+// the end of the text is removed, their offsets are valid for both the
+// original and the truncated text.
+func Truncate(text string, limit int, ellipsis string) (string, []*extract.ByteEntity) {
+	entities := extract.Entities(text)
+	if validate.TextLength(text) <= limit {
+		return text, entities
+	}
+
+	budget := limit - validate.TextLength(ellipsis)
+	if budget < 0 {
+		ellipsis = ""
+		budget = limit
+	}
+
+	// Find the longest prefix of whole grapheme clusters that fits. The
+	// clusters are counted one at a time, which matches TextLength because
+	// NFC never composes characters across a cluster boundary
+	cut := 0
+	length := 0
+	for cut < len(text) {
+		n := grapheme.Next(text[cut:])
+		l := validate.TextLength(text[cut : cut+n])
+		if length+l > budget {
+			break
+		}
+		length += l
+		cut += n
+	}
+
+	// Never cut through an entity
+	for _, e := range entities {
+		if e.ByteRange.Start < cut && cut < e.ByteRange.Stop {
+			cut = e.ByteRange.Start
+			break
+		}
+	}
+
+	truncated := strings.TrimRightFunc(text[:cut], unicode.IsSpace)
+
+	var kept []*extract.ByteEntity
+	for _, e := range entities {
+		if e.ByteRange.Stop <= len(truncated) {
+			kept = append(kept, e)
+		}
+	}
+	return truncated + ellipsis, kept
+}
@@ -0,0 +1,94 @@
+package truncate
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	goyaml "gopkg.in/yaml.v1"
+)
+
+type Conformance struct {
+	Tests map[string][]*Test
+}
+
+type Test struct {
+	Description string
+	Text        string
+	Limit       int
+	Ellipsis    string
+	Expected    struct {
+		Text     string
+		Entities []string
+	}
+}
+
+var cwd, _ = os.Getwd()
+var parentDir = path.Dir(cwd)
+var truncateYmlPath = path.Join(parentDir, "conformance", "truncate.yml")
+
+func TestTruncate(t *testing.T) {
+	contents, err := ioutil.ReadFile(truncateYmlPath)
+	if err != nil {
+		t.Errorf("Error reading truncate.yml: %v", err)
+		t.FailNow()
+	}
+
+	var conformance = &Conformance{}
+	err = goyaml.Unmarshal(contents, &conformance)
+	if err != nil {
+		t.Errorf("Error parsing truncate.yml: %v", err)
+		t.FailNow()
+	}
+
+	truncateTests, ok := conformance.Tests["truncate"]
+	if !ok {
+		t.Errorf("Conformance file did not contain 'truncate' key")
+		t.FailNow()
+	}
+
+	for _, test := range truncateTests {
+		text, entities := Truncate(test.Text, test.Limit, test.Ellipsis)
+		if text != test.Expected.Text {
+			t.Errorf(
+				"Truncate returned incorrect value for test [%s]. Expected:[%s] Got:[%s]\n",
+				test.Description,
+				test.Expected.Text,
+				text,
+			)
+		}
+
+		var actual []string
+		for _, e := range entities {
+			actual = append(actual, e.Text)
+			if text[e.ByteRange.Start:e.ByteRange.Stop] != e.Text {
+				t.Errorf(
+					"Truncate returned entity with incorrect offsets for test [%s]. Got:%s\n",
+					test.Description,
+					e,
+				)
+			}
+		}
+		if fmt.Sprint(actual) != fmt.Sprint(test.Expected.Entities) {
+			t.Errorf(
+				"Truncate returned incorrect entities for test [%s]. Expected:%v Got:%v\n",
+				test.Description,
+				test.Expected.Entities,
+				actual,
+			)
+		}
+	}
+}
+
+func ExampleTruncate() {
+	text, entities := Truncate("Hello @username, read http://example.com/article", 30, "…")
+	fmt.Println(text)
+	for _, e := range entities {
+		fmt.Printf("Entity:%s Range:%s\n", e.Text, e.Range)
+	}
+	// Output:
+	// Hello @username, read…
+	// Entity:@username Range:(6, 15)
+}
@@ -0,0 +1,67 @@
+package validate
+
+import (
+	"strings"
+
+	"github.com/interspace/byte-text-go/extract"
+)
+
+// LengthMode selects which characters of a text count towards its length
+type LengthMode int
+
+// LengthModes
+const (
+	// Count every character of the text, as TextLength does
+	CountAll LengthMode = iota
+
+	// Leave out the reply prefix: the mentions at the start of the text
+	// (See: extract.ReplyMentions) and the whitespace around them, e.g.
+	// "@alice @bob " in "@alice @bob hello"
+	ExcludeReplyMentions
+)
+
+// String implements the Stringer interface
+func (m LengthMode) String() string {
+	switch m {
+	case CountAll:
+		return "CountAll"
+	case ExcludeReplyMentions:
+		return "ExcludeReplyMentions"
+	}
+	return "Unknown"
+}
+
+// LengthArgs configures how TextLengthWithArgs counts the length of a text
+type LengthArgs struct {
+	Mode LengthMode
+
+	// The maximum number of leading mentions ExcludeReplyMentions leaves
+	// out. The mentions after them count towards the length. Zero means no
+	// limit
+	MaxReplyMentions int
+}
+
+// TextLengthWithArgs returns the length of the text as TextLength does,
+// leaving out the characters args.Mode excludes, and the number of characters
+// which were excluded. The sum of both is the TextLength of the text
+func TextLengthWithArgs(text string, args LengthArgs) (length int, excluded int) {
+	total := TextLength(text)
+	if args.Mode != ExcludeReplyMentions {
+		return total, 0
+	}
+
+	mentions := extract.ReplyMentions(text)
+	if len(mentions) == 0 {
+		return total, 0
+	}
+	if args.MaxReplyMentions > 0 && len(mentions) > args.MaxReplyMentions {
+		mentions = mentions[:args.MaxReplyMentions]
+	}
+
+	// The prefix ends after the whitespace following the last mention, so
+	// the separator before the body of the reply is not counted either
+	rest := text[mentions[len(mentions)-1].ByteRange.Stop:]
+	rest = strings.TrimLeftFunc(rest, extract.IsSpace)
+	length = TextLength(rest)
+	return length, total - length
+}
@@ -0,0 +1,159 @@
+package validate
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/interspace/byte-text-go/extract"
+	"github.com/interspace/byte-text-go/internal/grapheme"
+)
+
+// WeightedRange assigns a weight to the code points from Start to End
+// (inclusive)
+type WeightedRange struct {
+	Start  rune
+	End    rune
+	Weight int
+}
+
+// ParseConfig describes how ParseText weighs a text.
+//
+// Weights are expressed in units of 1/Scale characters: with a Scale of 100
+// a code point of weight 200 counts as two characters. Code points not
+// covered by any of the Ranges use DefaultWeight. An emoji sequence, such as
+// a flag, a keycap or a ZWJ sequence, weighs as much as its heaviest code
+// point. Every URL counts as TransformedURLLength characters, regardless of
+// its actual length.
+type ParseConfig struct {
+	MaxWeightedLength    int
+	Scale                int
+	DefaultWeight        int
+	TransformedURLLength int
+	Ranges               []WeightedRange
+}
+
+// DefaultParseConfig returns a configuration where Latin, Greek, Cyrillic
+// and other scripts below U+1100 as well as common punctuation count as one
+// character, while CJK characters, emoji and everything else count as two
+func DefaultParseConfig() ParseConfig {
+	return ParseConfig{
+		MaxWeightedLength:    280,
+		Scale:                100,
+		DefaultWeight:        200,
+		TransformedURLLength: 23,
+		Ranges: []WeightedRange{
+			{Start: 0x0000, End: 0x10FF, Weight: 100},
+			{Start: 0x2000, End: 0x200D, Weight: 100},
+			{Start: 0x2010, End: 0x201F, Weight: 100},
+			{Start: 0x2032, End: 0x2037, Weight: 100},
+		},
+	}
+}
+
+// ParseResult is the result of ParseText.
+//
+// DisplayRange and ValidRange are character offsets into the Unicode NFC of
+// the text, i.e. the same units TextLength counts. ValidByteRange holds the
+// byte offsets of the valid portion within the text as passed in, so
+// text[r.ValidByteRange.Start:r.ValidByteRange.Stop] is the longest prefix
+// that would pass validation.
+type ParseResult struct {
+	WeightedLength int
+	Permillage     int
+	IsValid        bool
+	DisplayRange   extract.Range
+	ValidRange     extract.Range
+	ValidByteRange extract.Range
+}
+
+// weight returns the weight of a single code point
+func (c *ParseConfig) weight(r rune) int {
+	for _, wr := range c.Ranges {
+		if r >= wr.Start && r <= wr.End {
+			return wr.Weight
+		}
+	}
+	return c.DefaultWeight
+}
+
+// ParseText weighs the given text according to config. Code points are
+// weighed one at a time, except for emoji sequences, such as a ZWJ family
+// sequence, a flag or a keycap, which are weighed as their heaviest code
+// point so that they count as a single emoji. URLs are found with
+// extract.URLs and count as config.TransformedURLLength characters each. The
+// text is valid if it is not empty, contains no invalid characters and its
+// weighted length does not exceed config.MaxWeightedLength
+func ParseText(text string, config ParseConfig) ParseResult {
+	scale := config.Scale
+	if scale <= 0 {
+		scale = 1
+	}
+	maxWeight := config.MaxWeightedLength * scale
+
+	urls := extract.URLs(text)
+	nextURL := 0
+
+	var (
+		weight     int
+		chars      int
+		validChars int
+		validBytes int
+		valid      = true
+	)
+
+	// Walk the text one NFC segment (or URL) at a time, so that characters
+	// are counted as they are displayed while byte offsets still refer to
+	// the original text
+	for offset := 0; offset < len(text); {
+		var segment string
+		var segmentWeight, segmentChars int
+		if nextURL < len(urls) && urls[nextURL].ByteRange.Start == offset {
+			segment = text[offset:urls[nextURL].ByteRange.Stop]
+			segmentWeight = config.TransformedURLLength * scale
+			segmentChars = utf8.RuneCountInString(formC.String(segment))
+			nextURL++
+		} else {
+			n := formC.NextBoundaryInString(text[offset:], true)
+			emoji := false
+			if cluster := grapheme.Next(text[offset:]); cluster > n && grapheme.IsEmoji(text[offset:offset+cluster]) {
+				n, emoji = cluster, true
+			}
+			if nextURL < len(urls) && offset+n > urls[nextURL].ByteRange.Start {
+				n = urls[nextURL].ByteRange.Start - offset
+			}
+			segment = text[offset : offset+n]
+			for _, r := range formC.String(segment) {
+				if w := config.weight(r); !emoji {
+					segmentWeight += w
+				} else if w > segmentWeight {
+					segmentWeight = w
+				}
+				segmentChars++
+			}
+		}
+
+		if valid && strings.ContainsAny(segment, invalidChars) {
+			valid = false
+		}
+
+		weight += segmentWeight
+		chars += segmentChars
+		offset += len(segment)
+		if valid && weight <= maxWeight {
+			validChars = chars
+			validBytes = offset
+		}
+	}
+
+	result := ParseResult{
+		WeightedLength: weight / scale,
+		IsValid:        valid && text != "" && weight <= maxWeight,
+		DisplayRange:   extract.Range{Start: 0, Stop: chars},
+		ValidRange:     extract.Range{Start: 0, Stop: validChars},
+		ValidByteRange: extract.Range{Start: 0, Stop: validBytes},
+	}
+	if config.MaxWeightedLength > 0 {
+		result.Permillage = result.WeightedLength * 1000 / config.MaxWeightedLength
+	}
+	return result
+}
@@ -0,0 +1,345 @@
+package validate
+
+import (
+	"net"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/interspace/byte-text-go/extract"
+)
+
+// URLArgs configures ParseURL
+type URLArgs struct {
+	// Require the URL to start with http:// or https://
+	RequireProtocol bool
+
+	// Allow unencoded non-ASCII characters in domain names
+	AllowUnicode bool
+
+	// Convert domain names with extract.HostToASCII and require the result
+	// to be a valid DNS name: at most 253 characters long, with labels of at
+	// most 63 characters following the rules of ParseURL. This rejects
+	// Unicode domains which are not valid IDNA 2008 names, as well as
+	// malformed punycode labels
+	CheckASCIIHost bool
+
+	// Reject domain names which extract.CheckHostSpoofing reports as
+	// suspicious: labels mixing scripts such as Latin and Cyrillic, labels
+	// made only of characters that look like Latin ones, and lookalikes of
+	// ProtectedDomains
+	RejectSpoofedHost bool
+	ProtectedDomains  []string
+}
+
+// HostType describes the form of a URL's host
+type HostType int
+
+const (
+	DomainHost HostType = iota
+	IPv4Host
+	IPv6Host
+	IPvFutureHost
+)
+
+func (t HostType) String() string {
+	switch t {
+	case DomainHost:
+		return "Domain"
+	case IPv4Host:
+		return "IPv4"
+	case IPv6Host:
+		return "IPv6"
+	case IPvFutureHost:
+		return "IPvFuture"
+	}
+	return "Unknown"
+}
+
+// URL holds the components of a URL parsed by ParseURL. Components which are
+// absent from the URL are empty
+type URL struct {
+	Scheme   string
+	Userinfo string
+
+	// The host without the brackets around IP literals or the zone ID
+	Host     string
+	HostType HostType
+
+	// The address of IPv4 and IPv6 hosts
+	IP net.IP
+
+	// The host of a domain converted with extract.HostToASCII, or empty if
+	// the host cannot be converted
+	ASCIIHost string
+
+	// The zone ID of an IPv6 host, with the %25 separator removed but
+	// otherwise still percent-encoded, e.g. "eth0" for [fe80::1%25eth0]
+	Zone string
+
+	// The port as written, guaranteed to be a decimal number from 0 to 65535
+	Port string
+
+	Path     string
+	Query    string
+	Fragment string
+}
+
+// ParseURL parses and validates a URL following the syntax of RFC 3986
+// (See: https://tools.ietf.org/html/rfc3986), with the following
+// restrictions:
+//   - A URL must have a host, and must not have a scheme without "//"
+//   - A domain name must have at least two labels, and its labels must not
+//     start or end with a hyphen or underscore
+//   - A port must not be empty
+//
+// IPv6 hosts may carry a zone ID as described in RFC 6874.
+//
+// Returns EmptyError for an empty text, an InvalidURLComponentError naming
+// the first component which is invalid, or a SpoofedHostError when
+// args.RejectSpoofedHost is set.
+func ParseURL(url string, args URLArgs) (*URL, error) {
+	if url == "" {
+		return nil, EmptyError{}
+	}
+
+	// The pattern matches any string, it only splits it into components
+	match := validateURLUnencodedRe.FindStringSubmatchIndex(url)
+	group := func(n int) (string, bool) {
+		start, end := match[n*2], match[n*2+1]
+		if start < 0 {
+			return "", false
+		}
+		return url[start:end], true
+	}
+
+	result := &URL{}
+	scheme, hasScheme := group(validateURLUnencodedGroupScheme)
+	if hasScheme && !validateURLSchemeRe.MatchString(scheme) ||
+		args.RequireProtocol && !protocolRe.MatchString(scheme) {
+		return nil, InvalidURLComponentError{Component: URLScheme, Value: scheme}
+	}
+	result.Scheme = scheme
+
+	authority, _ := group(validateURLUnencodedGroupAuthority)
+	if err := result.parseAuthority(authority, args); err != nil {
+		return nil, err
+	}
+
+	result.Path, _ = group(validateURLUnencodedGroupPath)
+	if !validateURLPathRe.MatchString(result.Path) {
+		return nil, InvalidURLComponentError{Component: URLPath, Value: result.Path}
+	}
+	result.Query, _ = group(validateURLUnencodedGroupQuery)
+	if !validateURLQueryRe.MatchString(result.Query) {
+		return nil, InvalidURLComponentError{Component: URLQuery, Value: result.Query}
+	}
+	result.Fragment, _ = group(validateURLUnencodedGroupFragment)
+	if !validateURLFragmentRe.MatchString(result.Fragment) {
+		return nil, InvalidURLComponentError{Component: URLFragment, Value: result.Fragment}
+	}
+	return result, nil
+}
+
+// parseAuthority parses the userinfo, host and port of a URL
+func (u *URL) parseAuthority(authority string, args URLArgs) error {
+	hostport := authority
+	if i := strings.LastIndex(authority, "@"); i >= 0 {
+		u.Userinfo = authority[:i]
+		if !validateURLUserinfoRe.MatchString(u.Userinfo) {
+			return InvalidURLComponentError{Component: URLUserinfo, Value: u.Userinfo}
+		}
+		hostport = authority[i+1:]
+	}
+
+	// A colon inside the brackets of an IP literal does not start a port
+	host := hostport
+	if i := strings.LastIndex(hostport, ":"); i > strings.LastIndex(hostport, "]") {
+		host = hostport[:i]
+		u.Port = hostport[i+1:]
+		if !validPort(u.Port) {
+			return InvalidURLComponentError{Component: URLPort, Value: u.Port}
+		}
+	}
+
+	if !u.parseHost(host, args) {
+		return InvalidURLComponentError{Component: URLHost, Value: host}
+	}
+	if args.RejectSpoofedHost && u.HostType == DomainHost {
+		if u.ASCIIHost == "" {
+			return InvalidURLComponentError{Component: URLHost, Value: host}
+		}
+		spoofing, err := extract.CheckHostSpoofing(u.ASCIIHost, args.ProtectedDomains)
+		if err != nil {
+			return err
+		}
+		if spoofing.Suspicious() {
+			return SpoofedHostError{Host: host, Spoofing: spoofing}
+		}
+	}
+	return nil
+}
+
+// validPort reports whether port is a number from 0 to 65535
+func validPort(port string) bool {
+	if port == "" || len(port) > 5 || strings.Trim(port, "0123456789") != "" {
+		return false
+	}
+	n, err := strconv.Atoi(port)
+	return err == nil && n <= 65535
+}
+
+// parseHost parses an IP literal in brackets, an IPv4 address or a domain
+// name, and reports whether it is valid
+func (u *URL) parseHost(host string, args URLArgs) bool {
+	if strings.HasPrefix(host, "[") && strings.HasSuffix(host, "]") {
+		literal := host[1 : len(host)-1]
+		if len(literal) > 0 && (literal[0] == 'v' || literal[0] == 'V') {
+			u.Host, u.HostType = literal, IPvFutureHost
+			return validIPvFuture(literal)
+		}
+
+		address := literal
+		if i := strings.Index(literal, "%25"); i >= 0 {
+			address, u.Zone = literal[:i], literal[i+3:]
+			if !validZone(u.Zone) {
+				return false
+			}
+		}
+		u.Host, u.HostType = address, IPv6Host
+		u.IP = net.ParseIP(address)
+		return strings.Contains(address, ":") && u.IP != nil
+	}
+
+	u.Host = host
+	if strings.Trim(host, "0123456789.") == "" {
+		u.HostType = IPv4Host
+		u.IP = parseIPv4(host)
+		return u.IP != nil
+	}
+	u.HostType = DomainHost
+	if !validDomain(host, args.AllowUnicode) {
+		return false
+	}
+	u.ASCIIHost, _ = extract.HostToASCII(host)
+	return !args.CheckASCIIHost || validDNSName(u.ASCIIHost)
+}
+
+// parseIPv4 parses a dotted-decimal IPv4 address whose parts have no leading
+// zeros. Returns nil if the address is invalid
+func parseIPv4(host string) net.IP {
+	parts := strings.Split(host, ".")
+	if len(parts) != 4 {
+		return nil
+	}
+	var ip [4]byte
+	for i, part := range parts {
+		if part == "" || len(part) > 1 && part[0] == '0' {
+			return nil
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil || n > 255 {
+			return nil
+		}
+		ip[i] = byte(n)
+	}
+	return net.IPv4(ip[0], ip[1], ip[2], ip[3])
+}
+
+// validIPvFuture reports whether literal has the form
+// "v" 1*HEXDIG "." 1*( unreserved / sub-delims / ":" )
+func validIPvFuture(literal string) bool {
+	dot := strings.Index(literal, ".")
+	if dot < 2 || dot == len(literal)-1 {
+		return false
+	}
+	for _, r := range literal[1:dot] {
+		if !isHexDigit(r) {
+			return false
+		}
+	}
+	for _, r := range literal[dot+1:] {
+		if !isUnreserved(r) && !strings.ContainsRune("!$&'()*+,;=:", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// validZone reports whether zone is a non-empty sequence of unreserved and
+// percent-encoded characters
+func validZone(zone string) bool {
+	if zone == "" {
+		return false
+	}
+	for i := 0; i < len(zone); i++ {
+		switch {
+		case zone[i] == '%':
+			if i+2 >= len(zone) || !isHexDigit(rune(zone[i+1])) || !isHexDigit(rune(zone[i+2])) {
+				return false
+			}
+			i += 2
+		case !isUnreserved(rune(zone[i])):
+			return false
+		}
+	}
+	return true
+}
+
+// validDomain reports whether host is a domain name with at least two
+// labels. Labels consist of letters, digits and hyphens, and start and end
+// with a letter or digit; labels other than the last two may also contain
+// underscores, and the last label must start with a letter. When
+// allowUnicode is set, non-ASCII characters count as letters
+func validDomain(host string, allowUnicode bool) bool {
+	labels := strings.Split(host, ".")
+	if len(labels) < 2 {
+		return false
+	}
+
+	isAlphanumeric := func(r rune) bool {
+		return isASCIIAlphanumeric(r) || allowUnicode && r >= utf8.RuneSelf
+	}
+	for i, label := range labels {
+		first, _ := utf8.DecodeRuneInString(label)
+		last, _ := utf8.DecodeLastRuneInString(label)
+		if label == "" || !isAlphanumeric(first) || !isAlphanumeric(last) {
+			return false
+		}
+		if i == len(labels)-1 && first >= '0' && first <= '9' {
+			return false
+		}
+		for _, r := range label {
+			if !isAlphanumeric(r) && r != '-' && (r != '_' || i >= len(labels)-2) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// validDNSName reports whether an ASCII domain name fits the length limits of
+// the DNS and its labels follow the rules of validDomain
+func validDNSName(host string) bool {
+	if host == "" || len(host) > 253 || !validDomain(host, false) {
+		return false
+	}
+	for _, label := range strings.Split(host, ".") {
+		if len(label) > 63 {
+			return false
+		}
+	}
+	return true
+}
+
+func isASCIIAlphanumeric(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9'
+}
+
+func isHexDigit(r rune) bool {
+	return r >= '0' && r <= '9' || r >= 'a' && r <= 'f' || r >= 'A' && r <= 'F'
+}
+
+func isUnreserved(r rune) bool {
+	return isASCIIAlphanumeric(r) || strings.ContainsRune("-._~", r)
+}
@@ -4,6 +4,7 @@ package validate
 import (
 	"fmt"
 	"strings"
+	"unicode"
 	"unicode/utf8"
 
 	"github.com/interspace/byte-text-go/extract"
@@ -16,17 +17,40 @@ const (
 
 var formC = norm.NFC
 
+// The username rules applied by the extract package
+var usernameOptions = extract.DefaultExtractorOptions()
+
 // TooLongError is returned when text is too long to be valid.
 // The value of the error is the actual length of the input string
 type TooLongError struct {
 	length    int
 	maxLength int
+	excluded  int
 }
 
 func (e TooLongError) Error() string {
+	if e.excluded > 0 {
+		return fmt.Sprintf("Length %d exceeds %d characters (%d excluded)", e.length, e.maxLength, e.excluded)
+	}
 	return fmt.Sprintf("Length %d exceeds %d characters", e.length, e.maxLength)
 }
 
+// Length returns the length of the text
+func (e TooLongError) Length() int {
+	return e.length
+}
+
+// MaxLength returns the maximum length the text exceeded
+func (e TooLongError) MaxLength() int {
+	return e.maxLength
+}
+
+// Excluded returns the number of characters which were left out of the
+// length, such as a reply prefix (See: LengthArgs)
+func (e TooLongError) Excluded() int {
+	return e.excluded
+}
+
 // EmptyError is returned when text is empty
 type EmptyError struct{}
 
@@ -36,10 +60,11 @@ func (e EmptyError) Error() string {
 
 // InvalidCharacterError is returned when text contains an invalid character.
 // This error embeds the value of the invalid character, and its byte-offset
-// within the input string
+// and rune-offset within the input string
 type InvalidCharacterError struct {
-	Character rune
-	Offset    int
+	Character  rune
+	Offset     int
+	RuneOffset int
 }
 
 func (e InvalidCharacterError) Error() string {
@@ -50,6 +75,142 @@ func (e InvalidCharacterError) Error() string {
 	)
 }
 
+// ValidationErrors is returned by TextValidate when ValidationArgs.AllErrors
+// is set. It holds every violation found in the text, in the order they are
+// checked: length errors first, then invalid characters by offset
+type ValidationErrors []error
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// TooShortError is returned when a username or hashtag is too short to be
+// valid
+type TooShortError struct {
+	length    int
+	minLength int
+}
+
+func (e TooShortError) Error() string {
+	return fmt.Sprintf("Length %d is less than %d characters", e.length, e.minLength)
+}
+
+// Length returns the length of the text
+func (e TooShortError) Length() int {
+	return e.length
+}
+
+// MinLength returns the minimum length the text fell short of
+func (e TooShortError) MinLength() int {
+	return e.minLength
+}
+
+// MissingPrefixError is returned when a username or hashtag does not start
+// with its @ or # sign
+type MissingPrefixError struct {
+	Prefix rune
+}
+
+func (e MissingPrefixError) Error() string {
+	return fmt.Sprintf("Text must start with [%s]", string(e.Prefix))
+}
+
+// SeparatorPosition describes why a separator is misplaced
+type SeparatorPosition int
+
+const (
+	// The separator is the first character of the username
+	LeadingSeparator SeparatorPosition = iota
+	// The separator is the last character of the username
+	TrailingSeparator
+	// The username already contains a separator
+	ExtraSeparator
+)
+
+func (p SeparatorPosition) String() string {
+	switch p {
+	case LeadingSeparator:
+		return "leading"
+	case TrailingSeparator:
+		return "trailing"
+	case ExtraSeparator:
+		return "extra"
+	}
+	return "Unknown"
+}
+
+// SeparatorError is returned when a username contains a separator character
+// (_ . or ~) where it is not allowed. This error embeds the separator, its
+// byte-offset within the input string and why it is not allowed there
+type SeparatorError struct {
+	Character rune
+	Offset    int
+	Position  SeparatorPosition
+}
+
+func (e SeparatorError) Error() string {
+	return fmt.Sprintf(
+		"Invalid %v separator [%s] found at byte offset %d",
+		e.Position,
+		string(e.Character),
+		e.Offset,
+	)
+}
+
+// NumericHashtagError is returned when a hashtag does not contain any
+// letters, e.g. #123
+type NumericHashtagError struct{}
+
+func (e NumericHashtagError) Error() string {
+	return "Hashtag must contain a letter"
+}
+
+// URLComponent names a part of a URL
+type URLComponent string
+
+const (
+	URLScheme   URLComponent = "scheme"
+	URLUserinfo URLComponent = "userinfo"
+	URLHost     URLComponent = "host"
+	URLPort     URLComponent = "port"
+	URLPath     URLComponent = "path"
+	URLQuery    URLComponent = "query"
+	URLFragment URLComponent = "fragment"
+)
+
+// InvalidURLComponentError is returned when a component of a URL is invalid.
+// This error embeds which component failed and its value, which is empty
+// when a required component is missing
+type InvalidURLComponentError struct {
+	Component URLComponent
+	Value     string
+}
+
+func (e InvalidURLComponentError) Error() string {
+	return fmt.Sprintf("Invalid URL %s [%s]", e.Component, e.Value)
+}
+
+// SpoofedHostError is returned when the host of a URL may imitate another
+// host. This error embeds the host and the analysis which flagged it
+type SpoofedHostError struct {
+	Host     string
+	Spoofing extract.HostSpoofing
+}
+
+func (e SpoofedHostError) Error() string {
+	switch {
+	case e.Spoofing.Lookalike != "":
+		return fmt.Sprintf("URL host [%s] looks like [%s]", e.Host, e.Spoofing.Lookalike)
+	case e.Spoofing.MixedScript:
+		return fmt.Sprintf("URL host [%s] mixes scripts", e.Host)
+	}
+	return fmt.Sprintf("URL host [%s] is confusable with a Latin name", e.Host)
+}
+
 // TextLength returns the length of the string as it would be displayed. This
 // is equivalent to the length of the Unicode NFC
 // (See: http://www.unicode.org/reports/tr15). This is needed in order to
@@ -71,6 +232,14 @@ func TextLength(text string) int {
 type ValidationArgs struct {
 	MaxLength  int
 	CanBeEmpty bool
+
+	// When set, TextValidate checks the whole text and returns every
+	// violation as a ValidationErrors, instead of stopping at the first one
+	AllErrors bool
+
+	// How the length compared with MaxLength is counted. The zero value
+	// counts every character
+	Length LengthArgs
 }
 
 // TextIsValid checks whether a string is a valid text and returns true or false
@@ -84,85 +253,174 @@ func TextIsValid(text string, args ValidationArgs) bool {
 // - The text is too long
 // - The text is empty
 // - The text contains invalid characters
+// If args.AllErrors is set, the error is a ValidationErrors holding all of
+// them. The length is counted following args.Length, and a TooLongError
+// reports how many characters were excluded from it.
 func TextValidate(text string, args ValidationArgs) error {
+	if args.AllErrors {
+		return textValidateAll(text, args)
+	}
+
 	if !args.CanBeEmpty && text == "" {
 		return EmptyError{}
-	} else if length := TextLength(text); length > args.MaxLength {
-		return TooLongError{length: length, maxLength: args.MaxLength}
+	} else if length, excluded := TextLengthWithArgs(text, args.Length); length > args.MaxLength {
+		return TooLongError{length: length, maxLength: args.MaxLength, excluded: excluded}
 	} else if i := strings.IndexAny(text, invalidChars); i > -1 {
-		r, _ := utf8.DecodeRuneInString(text[i:])
-		return InvalidCharacterError{Offset: i, Character: r}
+		return newInvalidCharacterError(text, i)
 	}
 	return nil
 }
 
-// UsernameIsValid returns true if the given text represents a valid @username
-func UsernameIsValid(username string) bool {
-	if username == "" {
-		return false
+func textValidateAll(text string, args ValidationArgs) error {
+	var errs ValidationErrors
+	if !args.CanBeEmpty && text == "" {
+		errs = append(errs, EmptyError{})
+	} else if length, excluded := TextLengthWithArgs(text, args.Length); length > args.MaxLength {
+		errs = append(errs, TooLongError{length: length, maxLength: args.MaxLength, excluded: excluded})
 	}
 
-	extracted := extract.MentionedScreenNames(username)
-	return len(extracted) == 1 && extracted[0].Text == username
+	runeOffset := 0
+	for i, r := range text {
+		if strings.ContainsRune(invalidChars, r) {
+			errs = append(errs, InvalidCharacterError{Character: r, Offset: i, RuneOffset: runeOffset})
+		}
+		runeOffset++
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
 }
 
-// HashtagIsValid returns true if the given text represents a valid #hashtag
-func HashtagIsValid(hashtag string) bool {
-	if hashtag == "" {
-		return false
+// newInvalidCharacterError returns the error for the character at byte
+// offset i of text
+func newInvalidCharacterError(text string, i int) InvalidCharacterError {
+	r, _ := utf8.DecodeRuneInString(text[i:])
+	return InvalidCharacterError{
+		Character:  r,
+		Offset:     i,
+		RuneOffset: utf8.RuneCountInString(text[:i]),
 	}
+}
 
-	extracted := extract.Hashtags(hashtag)
-	return len(extracted) == 1 && extracted[0].Text == hashtag
+// UsernameIsValid returns true if the given text represents a valid @username
+func UsernameIsValid(username string) bool {
+	return UsernameValidate(username) == nil
 }
 
-// URLIsValid returns true if the given text represents a valid URL
-func URLIsValid(url string, requireProtocol bool, allowUnicode bool) bool {
-	if url == "" {
-		return false
+// UsernameValidate checks whether the given text represents a valid
+// @username. Returns nil if it is valid. Otherwise, it returns an error in
+// the following cases:
+// - The text is empty
+// - The text does not start with an @ sign
+// - The username contains a character other than those
+//   extract.IsUsernameCharacter allows and the separators _ . ~
+// - A separator starts or ends the username, or follows another separator
+// - The username is too short or too long
+// As in extracted mentions, the @ sign may be repeated, e.g. "@@jack", and
+// the length of the username is its length in bytes after the @ signs.
+// Offsets within the errors are byte offsets into the text, including the @
+func UsernameValidate(username string) error {
+	if username == "" {
+		return EmptyError{}
 	}
 
-	match := validateURLUnencodedRe.FindStringSubmatchIndex(url)
-	if match == nil || url[match[0]:match[1]] != url {
-		return false
+	prefix, size := utf8.DecodeRuneInString(username)
+	if prefix != '@' && prefix != '＠' {
+		return MissingPrefixError{Prefix: '@'}
 	}
-
-	if requireProtocol {
-		schemeStart := match[validateURLUnencodedGroupScheme*2]
-		schemeEnd := match[validateURLUnencodedGroupScheme*2+1]
-		if !protocolRe.MatchString(url[schemeStart:schemeEnd]) {
-			return false
+	for size < len(username) {
+		r, w := utf8.DecodeRuneInString(username[size:])
+		if r != '@' && r != '＠' {
+			break
 		}
+		size += w
 	}
 
-	pathStart := match[validateURLUnencodedGroupPath*2]
-	pathEnd := match[validateURLUnencodedGroupPath*2+1]
-	if !validateURLPathRe.MatchString(url[pathStart:pathEnd]) {
-		return false
+	separators := usernameOptions.UsernameSeparators
+	name := username[size:]
+	separator := -1
+	for i, r := range name {
+		offset := size + i
+		switch {
+		case extract.IsUsernameCharacter(r):
+		case strings.ContainsRune(separators, r):
+			if i == 0 {
+				return SeparatorError{Character: r, Offset: offset, Position: LeadingSeparator}
+			} else if i == len(name)-1 {
+				return SeparatorError{Character: r, Offset: offset, Position: TrailingSeparator}
+			} else if separator >= 0 {
+				return SeparatorError{Character: r, Offset: offset, Position: ExtraSeparator}
+			}
+			separator = offset
+		default:
+			return newInvalidCharacterError(username, offset)
+		}
 	}
 
-	queryStart := match[validateURLUnencodedGroupQuery*2]
-	queryEnd := match[validateURLUnencodedGroupQuery*2+1]
-	if queryStart > 0 && !validateURLQueryRe.MatchString(
-		url[queryStart:queryEnd],
-	) {
-		return false
+	if len(name) < usernameOptions.MinUsernameLength {
+		return TooShortError{length: len(name), minLength: usernameOptions.MinUsernameLength}
+	} else if len(name) > usernameOptions.MaxUsernameLength {
+		return TooLongError{length: len(name), maxLength: usernameOptions.MaxUsernameLength}
 	}
+	return nil
+}
+
+// HashtagIsValid returns true if the given text represents a valid #hashtag
+func HashtagIsValid(hashtag string) bool {
+	return HashtagValidate(hashtag) == nil
+}
 
-	fragmentStart := match[validateURLUnencodedGroupFragment*2]
-	fragmentEnd := match[validateURLUnencodedGroupFragment*2+1]
-	if fragmentStart > 0 && !validateURLFragmentRe.MatchString(
-		url[fragmentStart:fragmentEnd],
-	) {
-		return false
+// HashtagValidate checks whether the given text represents a valid #hashtag.
+// Returns nil if it is valid. Otherwise, it returns an error in the
+// following cases:
+// - The text is empty
+// - The text does not start with a # sign
+// - There is nothing after the # sign
+// - The hashtag contains a character not allowed in hashtags
+// - The hashtag contains no letters, e.g. it is all numeric
+// Offsets within the errors are byte offsets into the text, including the #
+func HashtagValidate(hashtag string) error {
+	if hashtag == "" {
+		return EmptyError{}
 	}
 
-	authorityStart := match[validateURLUnencodedGroupAuthority*2]
-	authorityEnd := match[validateURLUnencodedGroupAuthority*2+1]
-	authority := url[authorityStart:authorityEnd]
+	prefix, size := utf8.DecodeRuneInString(hashtag)
+	if prefix != '#' && prefix != '＃' {
+		return MissingPrefixError{Prefix: '#'}
+	}
+	if len(hashtag) == size {
+		return TooShortError{length: 0, minLength: 1}
+	}
 
-	if allowUnicode {
-		return validateURLUnicodeAuthorityRe.MatchString(authority)
+	hasLetter := false
+	for i, r := range hashtag[size:] {
+		if !extract.IsHashtagCharacter(r) {
+			return newInvalidCharacterError(hashtag, size+i)
+		}
+		hasLetter = hasLetter || unicode.In(r, unicode.L, unicode.M)
+	}
+	if !hasLetter {
+		return NumericHashtagError{}
 	}
-	return validateURLAuthorityRe.MatchString(authority)
+	return nil
+}
+
+// URLIsValid returns true if the given text represents a valid URL
+func URLIsValid(url string, requireProtocol bool, allowUnicode bool) bool {
+	return URLValidate(url, requireProtocol, allowUnicode) == nil
+}
+
+// URLValidate checks whether the given text represents a valid URL. Returns
+// nil if it is valid. Otherwise, it returns EmptyError for an empty text, or
+// an InvalidURLComponentError naming the first component which is invalid.
+//
+// When requireProtocol is set the URL must start with http:// or https://.
+// When allowUnicode is set the host may contain unencoded non-ASCII
+// characters. See ParseURL for the rules applied, and URLArgs.CheckASCIIHost
+// to also check the punycode form of the host.
+func URLValidate(url string, requireProtocol bool, allowUnicode bool) error {
+	_, err := ParseURL(url, URLArgs{RequireProtocol: requireProtocol, AllowUnicode: allowUnicode})
+	return err
 }
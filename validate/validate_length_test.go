@@ -49,3 +49,61 @@ func TestTextLength(t *testing.T) {
 		}
 	}
 }
+
+func TestTextLengthExcludingReplyMentions(t *testing.T) {
+	contents, err := ioutil.ReadFile(validateYmlPath)
+	if err != nil {
+		t.Errorf("Error reading validate.yml: %v", err)
+		t.FailNow()
+	}
+
+	var testData map[interface{}]interface{}
+	err = goyaml.Unmarshal(contents, &testData)
+	if err != nil {
+		t.Fatalf("error unmarshaling data: %v\n", err)
+	}
+
+	tests, ok := testData["tests"]
+	if !ok {
+		t.Errorf("Conformance file was not in expected format.")
+		t.FailNow()
+	}
+
+	replyTests, ok := tests.(map[interface{}]interface{})["reply_lengths"]
+	if !ok {
+		t.Errorf("Conformance file did not contain reply_lengths tests")
+		t.FailNow()
+	}
+
+	for _, testCase := range replyTests.([]interface{}) {
+		test := testCase.(map[interface{}]interface{})
+		text, _ := test["text"]
+		description, _ := test["description"]
+		maxReplyMentions, _ := test["max_reply_mentions"]
+		expected, _ := test["expected"].(map[interface{}]interface{})
+
+		length, excluded := TextLengthWithArgs(text.(string), LengthArgs{
+			Mode:             ExcludeReplyMentions,
+			MaxReplyMentions: maxReplyMentions.(int),
+		})
+		if length != expected["length"] || excluded != expected["excluded"] {
+			t.Errorf(
+				"TextLengthWithArgs returned incorrect value for test [%s]. Expected:(%v, %v) Got:(%v, %v)",
+				description,
+				expected["length"],
+				expected["excluded"],
+				length,
+				excluded,
+			)
+		}
+
+		if length, excluded := TextLengthWithArgs(text.(string), LengthArgs{}); length != TextLength(text.(string)) || excluded != 0 {
+			t.Errorf(
+				"TextLengthWithArgs counted incorrectly by default for test [%s]. Got:(%v, %v)",
+				description,
+				length,
+				excluded,
+			)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package validate
+
+import (
+	"io/ioutil"
+	"testing"
+
+	goyaml "gopkg.in/yaml.v1"
+)
+
+func TestParseText(t *testing.T) {
+	contents, err := ioutil.ReadFile(validateYmlPath)
+	if err != nil {
+		t.Errorf("Error reading validate.yml: %v", err)
+		t.FailNow()
+	}
+
+	var testData map[interface{}]interface{}
+	err = goyaml.Unmarshal(contents, &testData)
+	if err != nil {
+		t.Fatalf("error unmarshaling data: %v\n", err)
+	}
+
+	tests, ok := testData["tests"]
+	if !ok {
+		t.Errorf("Conformance file was not in expected format.")
+		t.FailNow()
+	}
+
+	weightedTests, ok := tests.(map[interface{}]interface{})["weighted_texts"]
+	if !ok {
+		t.Errorf("Conformance file did not contain weighted_texts tests")
+		t.FailNow()
+	}
+
+	for _, testCase := range weightedTests.([]interface{}) {
+		test := testCase.(map[interface{}]interface{})
+		text, _ := test["text"]
+		description, _ := test["description"]
+		expected, _ := test["expected"].(map[interface{}]interface{})
+
+		actual := ParseText(text.(string), DefaultParseConfig())
+		if actual.WeightedLength != expected["weighted_length"] {
+			t.Errorf(
+				"ParseText returned incorrect weighted length for test [%s]. Expected:%v Got:%v",
+				description,
+				expected["weighted_length"],
+				actual.WeightedLength,
+			)
+		}
+
+		if actual.Permillage != expected["permillage"] {
+			t.Errorf(
+				"ParseText returned incorrect permillage for test [%s]. Expected:%v Got:%v",
+				description,
+				expected["permillage"],
+				actual.Permillage,
+			)
+		}
+
+		if actual.IsValid != expected["valid"] {
+			t.Errorf(
+				"ParseText returned incorrect validity for test [%s]. Expected:%v Got:%v",
+				description,
+				expected["valid"],
+				actual.IsValid,
+			)
+		}
+
+		displayRange := expected["display_range"].([]interface{})
+		if displayRange[0] != actual.DisplayRange.Start ||
+			displayRange[1] != actual.DisplayRange.Stop {
+			t.Errorf(
+				"ParseText returned incorrect display range for test [%s]. Expected:%v Got:%s",
+				description,
+				displayRange,
+				actual.DisplayRange,
+			)
+		}
+
+		validRange := expected["valid_range"].([]interface{})
+		if validRange[0] != actual.ValidRange.Start ||
+			validRange[1] != actual.ValidRange.Stop {
+			t.Errorf(
+				"ParseText returned incorrect valid range for test [%s]. Expected:%v Got:%s",
+				description,
+				validRange,
+				actual.ValidRange,
+			)
+		}
+
+		// The valid byte range must cover exactly the valid characters
+		validText := text.(string)[actual.ValidByteRange.Start:actual.ValidByteRange.Stop]
+		if TextLength(validText) != actual.ValidRange.Length() {
+			t.Errorf(
+				"ParseText returned inconsistent valid byte range for test [%s]. Got:%s for %s",
+				description,
+				actual.ValidByteRange,
+				actual.ValidRange,
+			)
+		}
+	}
+}
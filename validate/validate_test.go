@@ -8,3 +8,31 @@ import (
 var cwd, _ = os.Getwd()
 var parentDir = path.Dir(cwd)
 var validateYmlPath = path.Join(parentDir, "conformance", "validate.yml")
+
+// errorKind returns the name used for the type of err in validate.yml, or an
+// empty string for a nil error
+func errorKind(err error) string {
+	switch err := err.(type) {
+	case nil:
+		return ""
+	case EmptyError:
+		return "empty"
+	case TooShortError:
+		return "too_short"
+	case TooLongError:
+		return "too_long"
+	case MissingPrefixError:
+		return "missing_prefix"
+	case InvalidCharacterError:
+		return "invalid_character"
+	case SeparatorError:
+		return err.Position.String() + "_separator"
+	case NumericHashtagError:
+		return "numeric"
+	case InvalidURLComponentError:
+		return "invalid_" + string(err.Component)
+	case SpoofedHostError:
+		return "spoofed_host"
+	}
+	return err.Error()
+}
@@ -49,3 +49,97 @@ func TestTextIsValid(t *testing.T) {
 		}
 	}
 }
+
+func TestTextValidateAllErrors(t *testing.T) {
+	contents, err := ioutil.ReadFile(validateYmlPath)
+	if err != nil {
+		t.Errorf("Error reading validate.yml: %v", err)
+		t.FailNow()
+	}
+
+	var testData map[interface{}]interface{}
+	err = goyaml.Unmarshal(contents, &testData)
+	if err != nil {
+		t.Fatalf("error unmarshaling data: %v\n", err)
+	}
+
+	tests, ok := testData["tests"]
+	if !ok {
+		t.Errorf("Conformance file was not in expected format.")
+		t.FailNow()
+	}
+
+	allErrorsTests, ok := tests.(map[interface{}]interface{})["all_errors"]
+	if !ok {
+		t.Errorf("Conformance file did not contain all_errors tests")
+		t.FailNow()
+	}
+
+	for _, testCase := range allErrorsTests.([]interface{}) {
+		test := testCase.(map[interface{}]interface{})
+		text, _ := test["text"]
+		description, _ := test["description"]
+		maxLength, _ := test["max_length"]
+		expected, _ := test["expected"].([]interface{})
+
+		err := TextValidate(text.(string), ValidationArgs{MaxLength: maxLength.(int), AllErrors: true})
+		var actual ValidationErrors
+		if err != nil {
+			actual = err.(ValidationErrors)
+		}
+		if len(actual) != len(expected) {
+			t.Errorf(
+				"TextValidate returned incorrect number of errors for test [%s]. Expected:%v Got:%v",
+				description,
+				len(expected),
+				actual,
+			)
+			continue
+		}
+
+		for i, e := range expected {
+			e := e.(map[interface{}]interface{})
+			if kind := errorKind(actual[i]); kind != e["error"] {
+				t.Errorf(
+					"TextValidate returned incorrect error %d for test [%s]. Expected:%v Got:%v",
+					i,
+					description,
+					e["error"],
+					kind,
+				)
+			}
+			if invalid, ok := actual[i].(InvalidCharacterError); ok &&
+				(invalid.Offset != e["offset"] || invalid.RuneOffset != e["rune_offset"]) {
+				t.Errorf(
+					"TextValidate returned incorrect offsets for error %d for test [%s]. Expected:(%v, %v) Got:(%v, %v)",
+					i,
+					description,
+					e["offset"],
+					e["rune_offset"],
+					invalid.Offset,
+					invalid.RuneOffset,
+				)
+			}
+		}
+	}
+}
+
+func TestTextValidateExcludingReplyMentions(t *testing.T) {
+	args := ValidationArgs{MaxLength: 5, Length: LengthArgs{Mode: ExcludeReplyMentions, MaxReplyMentions: 2}}
+
+	if err := TextValidate("@alice @bob hello", args); err != nil {
+		t.Errorf("TextValidate returned an error for a reply within the limit: %v", err)
+	}
+
+	err := TextValidate("@alice @bob @carol hello", args)
+	tooLong, ok := err.(TooLongError)
+	if !ok || tooLong.Length() != 12 || tooLong.Excluded() != 12 {
+		t.Errorf("TextValidate returned incorrect error for a reply over the limit. Expected:(12, 12) Got:%v", err)
+	}
+
+	args.AllErrors = true
+	err = TextValidate("@alice @bob @carol hello", args)
+	if errs, ok := err.(ValidationErrors); !ok || len(errs) != 1 || errs[0].(TooLongError).Excluded() != 12 {
+		t.Errorf("TextValidate returned incorrect errors for a reply over the limit. Got:%v", err)
+	}
+}
@@ -91,4 +91,323 @@ func TestURLWithoutProtocol(t *testing.T) {
 			)
 		}
 	}
-}
\ No newline at end of file
+}
+
+func TestURLValidate(t *testing.T) {
+	contents, err := ioutil.ReadFile(validateYmlPath)
+	if err != nil {
+		t.Errorf("Error reading validate.yml: %v", err)
+		t.FailNow()
+	}
+
+	var testData map[interface{}]interface{}
+	err = goyaml.Unmarshal(contents, &testData)
+	if err != nil {
+		t.Fatalf("error unmarshaling data: %v\n", err)
+	}
+
+	tests, ok := testData["tests"]
+	if !ok {
+		t.Errorf("Conformance file was not in expected format.")
+		t.FailNow()
+	}
+
+	urlTests, ok := tests.(map[interface{}]interface{})["urls"]
+	if !ok {
+		t.Errorf("Conformance file did not contain urls tests")
+		t.FailNow()
+	}
+
+	for _, testCase := range urlTests.([]interface{}) {
+		test := testCase.(map[interface{}]interface{})
+		text, _ := test["text"]
+		description, _ := test["description"]
+		expected, _ := test["error"].(string)
+
+		actual := errorKind(URLValidate(text.(string), true, true))
+		if actual != expected {
+			t.Errorf(
+				"URLValidate returned incorrect error for test [%s]. Expected:%v Got:%v",
+				description,
+				expected,
+				actual,
+			)
+		}
+	}
+}
+
+func TestURLValidateWithoutProtocol(t *testing.T) {
+	contents, err := ioutil.ReadFile(validateYmlPath)
+	if err != nil {
+		t.Errorf("Error reading validate.yml: %v", err)
+		t.FailNow()
+	}
+
+	var testData map[interface{}]interface{}
+	err = goyaml.Unmarshal(contents, &testData)
+	if err != nil {
+		t.Fatalf("error unmarshaling data: %v\n", err)
+	}
+
+	tests, ok := testData["tests"]
+	if !ok {
+		t.Errorf("Conformance file was not in expected format.")
+		t.FailNow()
+	}
+
+	urlTests, ok := tests.(map[interface{}]interface{})["urls_without_protocol"]
+	if !ok {
+		t.Errorf("Conformance file did not contain urls_without_protocol tests")
+		t.FailNow()
+	}
+
+	for _, testCase := range urlTests.([]interface{}) {
+		test := testCase.(map[interface{}]interface{})
+		text, _ := test["text"]
+		description, _ := test["description"]
+		expected, _ := test["error"].(string)
+
+		actual := errorKind(URLValidate(text.(string), false, true))
+		if actual != expected {
+			t.Errorf(
+				"URLValidate returned incorrect error for test [%s]. Expected:%v Got:%v",
+				description,
+				expected,
+				actual,
+			)
+		}
+	}
+}
+
+func TestURLValidateASCII(t *testing.T) {
+	contents, err := ioutil.ReadFile(validateYmlPath)
+	if err != nil {
+		t.Errorf("Error reading validate.yml: %v", err)
+		t.FailNow()
+	}
+
+	var testData map[interface{}]interface{}
+	err = goyaml.Unmarshal(contents, &testData)
+	if err != nil {
+		t.Fatalf("error unmarshaling data: %v\n", err)
+	}
+
+	tests, ok := testData["tests"]
+	if !ok {
+		t.Errorf("Conformance file was not in expected format.")
+		t.FailNow()
+	}
+
+	urlTests, ok := tests.(map[interface{}]interface{})["urls_ascii"]
+	if !ok {
+		t.Errorf("Conformance file did not contain urls_ascii tests")
+		t.FailNow()
+	}
+
+	for _, testCase := range urlTests.([]interface{}) {
+		test := testCase.(map[interface{}]interface{})
+		text, _ := test["text"]
+		description, _ := test["description"]
+		expected, _ := test["expected"]
+		expectedError, _ := test["error"].(string)
+
+		if actual := URLIsValid(text.(string), true, false); actual != expected {
+			t.Errorf(
+				"URLIsValid returned incorrect value for test [%s]. Expected:%v Got:%v",
+				description,
+				expected,
+				actual,
+			)
+		}
+
+		if actual := errorKind(URLValidate(text.(string), true, false)); actual != expectedError {
+			t.Errorf(
+				"URLValidate returned incorrect error for test [%s]. Expected:%v Got:%v",
+				description,
+				expectedError,
+				actual,
+			)
+		}
+	}
+}
+
+func TestParseURLCheckASCIIHost(t *testing.T) {
+	contents, err := ioutil.ReadFile(validateYmlPath)
+	if err != nil {
+		t.Errorf("Error reading validate.yml: %v", err)
+		t.FailNow()
+	}
+
+	var testData map[interface{}]interface{}
+	err = goyaml.Unmarshal(contents, &testData)
+	if err != nil {
+		t.Fatalf("error unmarshaling data: %v\n", err)
+	}
+
+	tests, ok := testData["tests"]
+	if !ok {
+		t.Errorf("Conformance file was not in expected format.")
+		t.FailNow()
+	}
+
+	urlTests, ok := tests.(map[interface{}]interface{})["urls_ascii_host"]
+	if !ok {
+		t.Errorf("Conformance file did not contain urls_ascii_host tests")
+		t.FailNow()
+	}
+
+	args := URLArgs{RequireProtocol: true, AllowUnicode: true, CheckASCIIHost: true}
+	for _, testCase := range urlTests.([]interface{}) {
+		test := testCase.(map[interface{}]interface{})
+		text, _ := test["text"]
+		description, _ := test["description"]
+		expected, _ := test["expected"]
+		expectedError, _ := test["error"].(string)
+
+		_, err := ParseURL(text.(string), args)
+		if actual := err == nil; actual != expected {
+			t.Errorf(
+				"ParseURL returned incorrect value for test [%s]. Expected:%v Got:%v",
+				description,
+				expected,
+				actual,
+			)
+		}
+
+		if actual := errorKind(err); actual != expectedError {
+			t.Errorf(
+				"ParseURL returned incorrect error for test [%s]. Expected:%v Got:%v",
+				description,
+				expectedError,
+				actual,
+			)
+		}
+	}
+}
+
+func TestParseURLRejectSpoofedHost(t *testing.T) {
+	contents, err := ioutil.ReadFile(validateYmlPath)
+	if err != nil {
+		t.Errorf("Error reading validate.yml: %v", err)
+		t.FailNow()
+	}
+
+	var testData map[interface{}]interface{}
+	err = goyaml.Unmarshal(contents, &testData)
+	if err != nil {
+		t.Fatalf("error unmarshaling data: %v\n", err)
+	}
+
+	tests, ok := testData["tests"]
+	if !ok {
+		t.Errorf("Conformance file was not in expected format.")
+		t.FailNow()
+	}
+
+	urlTests, ok := tests.(map[interface{}]interface{})["urls_spoofed_host"]
+	if !ok {
+		t.Errorf("Conformance file did not contain urls_spoofed_host tests")
+		t.FailNow()
+	}
+
+	args := URLArgs{
+		AllowUnicode:      true,
+		RejectSpoofedHost: true,
+		ProtectedDomains:  []string{"paypal.com"},
+	}
+	for _, testCase := range urlTests.([]interface{}) {
+		test := testCase.(map[interface{}]interface{})
+		text, _ := test["text"]
+		description, _ := test["description"]
+		expected, _ := test["expected"]
+		expectedError, _ := test["error"].(string)
+
+		_, err := ParseURL(text.(string), args)
+		if actual := err == nil; actual != expected {
+			t.Errorf(
+				"ParseURL returned incorrect value for test [%s]. Expected:%v Got:%v",
+				description,
+				expected,
+				actual,
+			)
+		}
+
+		if actual := errorKind(err); actual != expectedError {
+			t.Errorf(
+				"ParseURL returned incorrect error for test [%s]. Expected:%v Got:%v",
+				description,
+				expectedError,
+				actual,
+			)
+		}
+	}
+}
+
+func TestParseURL(t *testing.T) {
+	contents, err := ioutil.ReadFile(validateYmlPath)
+	if err != nil {
+		t.Errorf("Error reading validate.yml: %v", err)
+		t.FailNow()
+	}
+
+	var testData map[interface{}]interface{}
+	err = goyaml.Unmarshal(contents, &testData)
+	if err != nil {
+		t.Fatalf("error unmarshaling data: %v\n", err)
+	}
+
+	tests, ok := testData["tests"]
+	if !ok {
+		t.Errorf("Conformance file was not in expected format.")
+		t.FailNow()
+	}
+
+	parseTests, ok := tests.(map[interface{}]interface{})["parsed_urls"]
+	if !ok {
+		t.Errorf("Conformance file did not contain parsed_urls tests")
+		t.FailNow()
+	}
+
+	for _, testCase := range parseTests.([]interface{}) {
+		test := testCase.(map[interface{}]interface{})
+		text, _ := test["text"]
+		description, _ := test["description"]
+		expected, _ := test["expected"].(map[interface{}]interface{})
+
+		u, err := ParseURL(text.(string), URLArgs{})
+		if err != nil {
+			t.Errorf("ParseURL failed for test [%s]: %v", description, err)
+			continue
+		}
+
+		var ip string
+		if u.IP != nil {
+			ip = u.IP.String()
+		}
+		actual := map[string]string{
+			"scheme":     u.Scheme,
+			"userinfo":   u.Userinfo,
+			"host":       u.Host,
+			"host_type":  u.HostType.String(),
+			"ip":         ip,
+			"ascii_host": u.ASCIIHost,
+			"zone":       u.Zone,
+			"port":       u.Port,
+			"path":       u.Path,
+			"query":      u.Query,
+			"fragment":   u.Fragment,
+		}
+		for name, value := range actual {
+			want, _ := expected[name].(string)
+			if value != want {
+				t.Errorf(
+					"ParseURL returned incorrect %s for test [%s]. Expected:%v Got:%v",
+					name,
+					description,
+					want,
+					value,
+				)
+			}
+		}
+	}
+}